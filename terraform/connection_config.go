@@ -5,10 +5,14 @@ import (
 )
 
 type terraformConfig struct {
-	ConfigurationFilePaths []string `hcl:"configuration_file_paths,optional" steampipe:"watch"`
-	Paths                  []string `hcl:"paths,optional" steampipe:"watch"`
-	PlanFilePaths          []string `hcl:"plan_file_paths,optional" steampipe:"watch"`
-	StateFilePaths         []string `hcl:"state_file_paths,optional" steampipe:"watch"`
+	ConfigurationFilePaths []string          `hcl:"configuration_file_paths,optional" steampipe:"watch"`
+	Paths                  []string          `hcl:"paths,optional" steampipe:"watch"`
+	PlanFilePaths          []string          `hcl:"plan_file_paths,optional" steampipe:"watch"`
+	StateFilePaths         []string          `hcl:"state_file_paths,optional" steampipe:"watch"`
+	HTTPBackendAddresses   []string          `hcl:"http_backend_addresses,optional"`
+	HTTPBackendUsername    *string           `hcl:"http_backend_username,optional"`
+	HTTPBackendPassword    *string           `hcl:"http_backend_password,optional"`
+	HTTPBackendHeaders     map[string]string `hcl:"http_backend_headers,optional"`
 }
 
 func ConfigInstance() interface{} {
@@ -0,0 +1,150 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+// States stored in an HTTP backend are fetched with a GET request on the
+// address of the backend, as done by terraform state pull. The states are
+// only read, so they are not locked.
+const httpBackendTimeout = 30 * time.Second
+
+// The fetched states are cached in memory for the connection, so that the
+// backends are requested once for all the tables and files of a query
+const httpBackendCacheTTL = 5 * time.Minute
+
+// tfStateList lists the same files as tfConfigList, along with the states
+// stored in the configured HTTP backends. It is only used by the tables
+// reading states, so that the other tables never request the backends.
+func tfStateList(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	addresses := GetConfig(d.Connection).HTTPBackendAddresses
+
+	// States stored in HTTP backends do not belong to a workspace
+	if d.EqualsQuals["workspace"] != nil {
+		addresses = nil
+	}
+
+	// If the path was requested through qualifier, check if it is the address
+	// of an HTTP backend
+	if d.EqualsQuals["path"] != nil {
+		path := d.EqualsQualString("path")
+		for _, address := range addresses {
+			if path == address {
+				streamHTTPBackendState(ctx, d, address)
+				return nil, nil
+			}
+		}
+		return tfConfigList(ctx, d, h)
+	}
+
+	if _, err := tfConfigList(ctx, d, h); err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		streamHTTPBackendState(ctx, d, address)
+	}
+	return nil, nil
+}
+
+// streamHTTPBackendState streams the state stored in an HTTP backend, unless
+// the backend does not store any state yet. Like the paths which cannot be
+// read, the backends which cannot be reached are skipped, so that they do not
+// fail the queries on the other states.
+func streamHTTPBackendState(ctx context.Context, d *plugin.QueryData, address string) {
+	content, err := getHTTPBackendState(ctx, d, address)
+	if err != nil {
+		plugin.Logger(ctx).Error("tfStateList.httpBackendAddresses", "get_state_error", err, "address", address)
+		return
+	}
+	if len(content) == 0 {
+		return
+	}
+	d.StreamListItem(ctx, filePath{
+		Path:               address,
+		IsTFStateFilePath:  true,
+		HTTPBackendAddress: address,
+	})
+}
+
+// getHTTPBackendState returns the state stored in an HTTP backend, from the
+// connection cache if it has already been fetched, or an empty state if the
+// backend does not store any state yet
+func getHTTPBackendState(ctx context.Context, d *plugin.QueryData, address string) ([]byte, error) {
+	cacheKey := "http_backend_state_" + address
+	if cachedContent, ok := d.ConnectionCache.Get(ctx, cacheKey); ok {
+		return cachedContent.([]byte), nil
+	}
+
+	content, err := fetchHTTPBackendState(ctx, GetConfig(d.Connection), address)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.ConnectionCache.SetWithTTL(ctx, cacheKey, content, httpBackendCacheTTL); err != nil {
+		plugin.Logger(ctx).Warn("getHTTPBackendState", "cache_set_error", err, "address", address)
+	}
+	return content, nil
+}
+
+// fetchHTTPBackendState requests the state stored in an HTTP backend
+func fetchHTTPBackendState(ctx context.Context, config terraformConfig, address string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The credentials default to the environment variables used by the HTTP
+	// backend of Terraform
+	username := os.Getenv("TF_HTTP_USERNAME")
+	if config.HTTPBackendUsername != nil {
+		username = *config.HTTPBackendUsername
+	}
+	password := os.Getenv("TF_HTTP_PASSWORD")
+	if config.HTTPBackendPassword != nil {
+		password = *config.HTTPBackendPassword
+	}
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+	for name, value := range config.HTTPBackendHeaders {
+		req.Header.Set(name, value)
+	}
+
+	client := &http.Client{Timeout: httpBackendTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// The backend responds with no content, or not found, if there is no state
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent, http.StatusNotFound:
+		return []byte{}, nil
+	default:
+		return nil, fmt.Errorf("failed to get state from %s: unexpected response status %s", address, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state from %s: %v", address, err)
+	}
+	return content, nil
+}
+
+// readStateFileContent returns the content of a file streamed by tfStateList.
+// The states of HTTP backends are read from the connection cache, and never
+// written to disk as they may contain secrets.
+func readStateFileContent(ctx context.Context, d *plugin.QueryData, pathInfo filePath) ([]byte, error) {
+	if pathInfo.HTTPBackendAddress != "" {
+		return getHTTPBackendState(ctx, d, pathInfo.HTTPBackendAddress)
+	}
+	return os.ReadFile(pathInfo.Path)
+}
@@ -0,0 +1,124 @@
+package terraform
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchHTTPBackendState(t *testing.T) {
+	const state = `{"version":4,"terraform_version":"1.5.0","serial":1,"lineage":"test","outputs":{},"resources":[]}`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/state", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "terraform" || password != "secret" || r.Header.Get("X-Team") != "platform" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(state))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Setenv("TF_HTTP_USERNAME", "")
+	t.Setenv("TF_HTTP_PASSWORD", "")
+
+	username, password := "terraform", "secret"
+	config := terraformConfig{
+		HTTPBackendUsername: &username,
+		HTTPBackendPassword: &password,
+		HTTPBackendHeaders:  map[string]string{"X-Team": "platform"},
+	}
+
+	content, err := fetchHTTPBackendState(context.Background(), config, server.URL+"/state")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(content) != state {
+		t.Errorf("unexpected state: %s", content)
+	}
+
+	// Backends without any state respond with no content or not found
+	for _, address := range []string{server.URL + "/empty", server.URL + "/missing"} {
+		content, err := fetchHTTPBackendState(context.Background(), config, address)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", address, err)
+		}
+		if len(content) != 0 {
+			t.Errorf("unexpected state for %s: %s", address, content)
+		}
+	}
+
+	if _, err := fetchHTTPBackendState(context.Background(), terraformConfig{}, server.URL+"/state"); err == nil {
+		t.Error("expected an error without credentials")
+	}
+}
+
+func TestHTTPBackendStateRows(t *testing.T) {
+	const state = `{
+  "version": 4,
+  "terraform_version": "1.5.0",
+  "serial": 1,
+  "lineage": "test",
+  "outputs": {
+    "bucket_name": {"value": "logs", "type": "string"}
+  },
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"schema_version": 0, "attributes": {"bucket": "logs"}}
+      ]
+    }
+  ]
+}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(state))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	address := server.URL + "/state"
+	content, err := fetchHTTPBackendState(ctx, terraformConfig{}, address)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The state is parsed from memory, so the rows have no lines or source
+	tfResources, err := getTerraformStateResources(ctx, true, address, content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tfResources) != 1 {
+		t.Fatalf("got %d resources, want 1", len(tfResources))
+	}
+	tfResource := tfResources[0]
+	if tfResource.Address != "aws_s3_bucket.logs" || tfResource.Path != address || tfResource.StartLine != 0 || tfResource.Source != "" {
+		t.Errorf("unexpected resource: address %s, path %s, start line %d, source %q", tfResource.Address, tfResource.Path, tfResource.StartLine, tfResource.Source)
+	}
+	if attributes, ok := tfResource.Attributes.(map[string]interface{}); !ok || attributes["bucket"] != "logs" {
+		t.Errorf("unexpected resource attributes: %v", tfResource.Attributes)
+	}
+
+	tfOutputs, err := getTerraformStateOutputs(ctx, true, address, content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tfOutputs) != 1 {
+		t.Fatalf("got %d outputs, want 1", len(tfOutputs))
+	}
+	tfOutput := tfOutputs[0]
+	if tfOutput.Name != "bucket_name" || tfOutput.Path != address || tfOutput.StartLine != 0 || tfOutput.Value != `"logs"` {
+		t.Errorf("unexpected output: name %s, path %s, start line %d, value %s", tfOutput.Name, tfOutput.Path, tfOutput.StartLine, tfOutput.Value)
+	}
+}
@@ -0,0 +1,416 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// terraformConfigModulesManifestName is the manifest of the modules installed
+// by terraform init, relative to the root module directory. It uses the same
+// format as the modules manifest of the configuration snapshot of binary plans.
+const terraformConfigModulesManifestName = ".terraform/modules/modules.json"
+
+var terraformConfigSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+	},
+}
+
+// TerraformConfigResource represents a resource block declared in the
+// configuration of a root module or one of its child modules
+type TerraformConfigResource struct {
+	// The address of the resource without any instance keys, e.g.
+	// module.vpc.aws_subnet.private
+	Address       string
+	ModuleAddress string
+	Mode          string
+	Type          string
+	Name          string
+	HasCount      bool
+	HasForEach    bool
+	// The value of count or the keys of for_each, if they are constant
+	Count       *int
+	ForEachKeys []string
+	Path        string
+	StartLine   int
+	EndLine     int
+}
+
+// TerraformConfig represents the resources declared in the configuration of
+// a root module and its child modules
+type TerraformConfig struct {
+	Resources []TerraformConfigResource
+	// The addresses of the module calls whose source could not be read, e.g.
+	// remote modules which have not been installed by terraform init
+	UnresolvedModules []string
+}
+
+// getTerraformConfig reads the resources declared in the configuration of the
+// root module in the given directory, along with its child modules. Child
+// modules are located using the modules manifest written by terraform init, or
+// by their source if they are local modules.
+func getTerraformConfig(rootDir string) (*TerraformConfig, error) {
+	config := new(TerraformConfig)
+
+	// The modules manifest is only available once the root module has been
+	// initialized
+	var moduleDirs map[string]string
+	manifest, err := os.ReadFile(filepath.Join(rootDir, terraformConfigModulesManifestName))
+	if err == nil {
+		var content struct {
+			Modules []terraformBinaryPlanModule `json:"Modules"`
+		}
+		if err := json.Unmarshal(manifest, &content); err != nil {
+			return nil, fmt.Errorf("failed to decode the modules manifest of %s: %v", rootDir, err)
+		}
+		moduleDirs = map[string]string{}
+		for _, module := range content.Modules {
+			moduleDirs[module.Key] = filepath.Join(rootDir, module.Dir)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	err = readTerraformConfigModule(parser, config, rootDir, "", "", moduleDirs, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(config.Resources, func(i, j int) bool {
+		return config.Resources[i].Address < config.Resources[j].Address
+	})
+	return config, nil
+}
+
+// Module calls can be recursive when using local sources, so limit the depth
+// of the module tree
+const terraformConfigMaxModuleDepth = 16
+
+func readTerraformConfigModule(parser *hclparse.Parser, config *TerraformConfig, dir string, moduleKey string, moduleAddress string, moduleDirs map[string]string, depth int) error {
+	blocks, err := readTerraformConfigBlocks(parser, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, block := range blocks {
+		switch block.Type {
+		case "resource", "data":
+			config.Resources = append(config.Resources, buildTerraformConfigResource(moduleAddress, block))
+
+		case "module":
+			name := block.Labels[0]
+			childKey := name
+			if moduleKey != "" {
+				childKey = moduleKey + "." + name
+			}
+			childAddress := "module." + name
+			if moduleAddress != "" {
+				childAddress = moduleAddress + "." + childAddress
+			}
+
+			childDir, ok := getTerraformConfigModuleDir(dir, childKey, block, moduleDirs)
+			if !ok || depth >= terraformConfigMaxModuleDepth {
+				config.UnresolvedModules = append(config.UnresolvedModules, childAddress)
+				continue
+			}
+			err := readTerraformConfigModule(parser, config, childDir, childKey, childAddress, moduleDirs, depth+1)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readTerraformConfigBlocks returns the resource, data and module blocks of
+// the configuration files of a module directory
+func readTerraformConfigBlocks(parser *hclparse.Parser, dir string) (hcl.Blocks, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks hcl.Blocks
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+
+		// Override files are merged into the primary files by Terraform, which
+		// is not supported here
+		var file *hcl.File
+		var diags hcl.Diagnostics
+		switch {
+		case isTerraformOverrideFile(name):
+			continue
+		case strings.HasSuffix(name, ".tf"):
+			file, diags = parser.ParseHCLFile(filepath.Join(dir, name))
+		case strings.HasSuffix(name, ".tf.json"):
+			file, diags = parser.ParseJSONFile(filepath.Join(dir, name))
+		default:
+			continue
+		}
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse %s: %v", filepath.Join(dir, name), diags.Error())
+		}
+
+		content, _, _ := file.Body.PartialContent(terraformConfigSchema)
+		blocks = append(blocks, content.Blocks...)
+	}
+
+	return blocks, nil
+}
+
+// isTerraformOverrideFile returns true for the override.tf[.json] and
+// *_override.tf[.json] files of a module directory
+func isTerraformOverrideFile(name string) bool {
+	var base string
+	switch {
+	case strings.HasSuffix(name, ".tf"):
+		base = strings.TrimSuffix(name, ".tf")
+	case strings.HasSuffix(name, ".tf.json"):
+		base = strings.TrimSuffix(name, ".tf.json")
+	default:
+		return false
+	}
+	return base == "override" || strings.HasSuffix(base, "_override")
+}
+
+// parseTerraformConfigFile parses the content of a configuration file, using
+// the JSON syntax for .tf.json files
+func parseTerraformConfigFile(parser *hclparse.Parser, path string, content []byte) (*hcl.File, hcl.Diagnostics) {
+	if strings.HasSuffix(path, ".json") {
+		return parser.ParseJSON(content, path)
+	}
+	return parser.ParseHCL(content, path)
+}
+
+// getTerraformConfigModuleDir returns the directory of the source of a module
+// call, either from the modules manifest or, for local modules, relative to
+// the directory of the calling module
+func getTerraformConfigModuleDir(dir string, moduleKey string, block *hcl.Block, moduleDirs map[string]string) (string, bool) {
+	if moduleDirs != nil {
+		childDir, ok := moduleDirs[moduleKey]
+		return childDir, ok
+	}
+
+	attributes, _ := block.Body.JustAttributes()
+	attr, ok := attributes["source"]
+	if !ok {
+		return "", false
+	}
+	value, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || value.Type() != cty.String || !value.IsKnown() || value.IsNull() {
+		return "", false
+	}
+	source := value.AsString()
+	if !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+		return "", false
+	}
+	return filepath.Join(dir, source), true
+}
+
+func buildTerraformConfigResource(moduleAddress string, block *hcl.Block) TerraformConfigResource {
+	mode := "managed"
+	if block.Type == "data" {
+		mode = "data"
+	}
+	resource := TerraformConfigResource{
+		Address:       buildResourceInstanceAddress(moduleAddress, mode, block.Labels[0], block.Labels[1], nil),
+		ModuleAddress: moduleAddress,
+		Mode:          mode,
+		Type:          block.Labels[0],
+		Name:          block.Labels[1],
+		Path:          block.DefRange.Filename,
+		StartLine:     block.DefRange.Start.Line,
+		EndLine:       block.DefRange.End.Line,
+	}
+	if body, ok := block.Body.(*hclsyntax.Body); ok {
+		resource.EndLine = body.SrcRange.End.Line
+	}
+
+	// Only constant values of count and for_each can be evaluated without the
+	// values of the variables and other objects of the module
+	attributes, _ := block.Body.JustAttributes()
+	if attr, ok := attributes["count"]; ok {
+		resource.HasCount = true
+		if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.IsWhollyKnown() && !value.IsNull() && value.Type() == cty.Number {
+			if count, accuracy := value.AsBigFloat().Int64(); accuracy == 0 {
+				n := int(count)
+				resource.Count = &n
+			}
+		}
+	}
+	if attr, ok := attributes["for_each"]; ok {
+		resource.HasForEach = true
+		if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.IsWhollyKnown() && !value.IsNull() && (value.Type().IsObjectType() || value.Type().IsMapType()) {
+			resource.ForEachKeys = []string{}
+			for key := range value.AsValueMap() {
+				resource.ForEachKeys = append(resource.ForEachKeys, key)
+			}
+			sort.Strings(resource.ForEachKeys)
+		}
+	}
+
+	return resource
+}
+
+// buildTerraformResourceReconciliations matches the resource instances of a
+// state with the resources declared in the configuration of its root module,
+// by address, and returns a row for each state instance and each resource
+// declared without any instance
+func buildTerraformResourceReconciliations(root string, path string, config *TerraformConfig, stateResources []*terraformResource) []*terraformResourceReconciliation {
+	var reconciliations []*terraformResourceReconciliation
+
+	configResources := map[string]TerraformConfigResource{}
+	for _, resource := range config.Resources {
+		configResources[resource.Address] = resource
+	}
+
+	instanceCounts := map[string]int{}
+	for _, stateResource := range stateResources {
+		moduleAddress := getModuleConfigAddress(stateResource.ModuleAddress)
+		configAddress := buildResourceInstanceAddress(moduleAddress, stateResource.Mode, stateResource.Type, stateResource.Name, nil)
+
+		reconciliation := &terraformResourceReconciliation{
+			Address:       stateResource.Address,
+			ConfigAddress: configAddress,
+			Mode:          stateResource.Mode,
+			Type:          stateResource.Type,
+			Name:          stateResource.Name,
+			ModuleAddress: stateResource.ModuleAddress,
+			IndexKey:      stateResource.IndexKey,
+			Deposed:       stateResource.Deposed,
+			IsDeposed:     stateResource.IsDeposed,
+			Root:          root,
+			Path:          path,
+		}
+
+		configResource, ok := configResources[configAddress]
+		switch {
+		case ok && isTerraformConfigResourceInstance(configResource, stateResource.IndexKey):
+			reconciliation.Status = "managed"
+		case ok:
+			reconciliation.Status = "orphaned_instance"
+		case isInTerraformConfigModules(moduleAddress, config.UnresolvedModules):
+			// The configuration of the module is not available, so the resource
+			// cannot be reconciled
+			continue
+		default:
+			reconciliation.Status = "state_only"
+		}
+		if ok {
+			reconciliation.ConfigPath = configResource.Path
+			reconciliation.StartLine = configResource.StartLine
+			reconciliation.EndLine = configResource.EndLine
+			instanceCounts[configAddress]++
+		}
+
+		reconciliations = append(reconciliations, reconciliation)
+	}
+
+	for _, configResource := range config.Resources {
+		if instanceCounts[configResource.Address] > 0 || !hasTerraformConfigResourceInstances(configResource) {
+			continue
+		}
+		reconciliations = append(reconciliations, &terraformResourceReconciliation{
+			Address:       configResource.Address,
+			ConfigAddress: configResource.Address,
+			Status:        "config_only",
+			Mode:          configResource.Mode,
+			Type:          configResource.Type,
+			Name:          configResource.Name,
+			ModuleAddress: configResource.ModuleAddress,
+			ConfigPath:    configResource.Path,
+			StartLine:     configResource.StartLine,
+			EndLine:       configResource.EndLine,
+			Root:          root,
+			Path:          path,
+		})
+	}
+
+	sort.SliceStable(reconciliations, func(i, j int) bool {
+		return reconciliations[i].Address < reconciliations[j].Address
+	})
+	return reconciliations
+}
+
+// isTerraformConfigResourceInstance returns whether the key of a state
+// instance matches the count or for_each of the resource declared in the
+// configuration. Resources using count have number keys, resources using
+// for_each have string keys, and other resources have a single instance
+// without any key.
+func isTerraformConfigResourceInstance(resource TerraformConfigResource, indexKey interface{}) bool {
+	switch key := indexKey.(type) {
+	case float64:
+		if !resource.HasCount {
+			return false
+		}
+		return resource.Count == nil || int(key) < *resource.Count
+	case string:
+		if !resource.HasForEach {
+			return false
+		}
+		if resource.ForEachKeys == nil {
+			return true
+		}
+		i := sort.SearchStrings(resource.ForEachKeys, key)
+		return i < len(resource.ForEachKeys) && resource.ForEachKeys[i] == key
+	}
+	return !resource.HasCount && !resource.HasForEach
+}
+
+// hasTerraformConfigResourceInstances returns whether a resource declared in
+// the configuration expands to at least one instance, i.e., it does not have a
+// count of 0 or an empty for_each
+func hasTerraformConfigResourceInstances(resource TerraformConfigResource) bool {
+	if resource.Count != nil && *resource.Count == 0 {
+		return false
+	}
+	if resource.ForEachKeys != nil && len(resource.ForEachKeys) == 0 {
+		return false
+	}
+	return true
+}
+
+// isInTerraformConfigModules returns whether a module is one of the given
+// modules, or one of their descendants
+func isInTerraformConfigModules(moduleAddress string, modules []string) bool {
+	for _, module := range modules {
+		if moduleAddress == module || strings.HasPrefix(moduleAddress, module+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// getTerraformExpressionValue returns the value of a constant expression, or
+// the source of the expression if it cannot be evaluated, e.g. if it contains
+// references or function calls
+func getTerraformExpressionValue(expr hcl.Expression, content []byte) interface{} {
+	value, diags := expr.Value(nil)
+	if !diags.HasErrors() && value.IsWhollyKnown() {
+		if raw, err := ctyjson.Marshal(value, value.Type()); err == nil {
+			var constantValue interface{}
+			if err := json.Unmarshal(raw, &constantValue); err == nil {
+				return constantValue
+			}
+		}
+	}
+	return string(expr.Range().SliceBytes(content))
+}
@@ -0,0 +1,90 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuildTerraformResourceReconciliations(t *testing.T) {
+	dir := t.TempDir()
+	config := `
+resource "aws_instance" "web" {}
+
+resource "aws_instance" "disabled" {
+  count = 0
+}
+
+resource "aws_instance" "off" {
+  count = 0
+}
+
+resource "aws_s3_bucket" "none" {
+  for_each = {}
+}
+
+resource "aws_s3_bucket" "missing" {
+  for_each = {
+    logs = "logs"
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Only override.tf and *_override.tf files are override files
+	if err := os.WriteFile(filepath.Join(dir, "nooverride.tf"), []byte(`resource "aws_instance" "extra" {}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tfConfig, err := getTerraformConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateResources := []*terraformResource{
+		{Address: "aws_instance.web", Mode: "managed", Type: "aws_instance", Name: "web"},
+		{Address: "aws_instance.disabled[0]", Mode: "managed", Type: "aws_instance", Name: "disabled", IndexKey: float64(0)},
+		{Address: "aws_instance.extra", Mode: "managed", Type: "aws_instance", Name: "extra"},
+	}
+
+	got := map[string]string{}
+	for _, reconciliation := range buildTerraformResourceReconciliations(dir, "terraform.tfstate", tfConfig, stateResources) {
+		got[reconciliation.Address] = reconciliation.Status
+	}
+	want := map[string]string{
+		"aws_instance.web":         "managed",
+		"aws_instance.extra":       "managed",
+		"aws_instance.disabled[0]": "orphaned_instance",
+		"aws_s3_bucket.missing":    "config_only",
+	}
+	if len(got) != len(want) {
+		t.Errorf("got reconciliations %v, want %v", got, want)
+	}
+	for address, status := range want {
+		if got[address] != status {
+			t.Errorf("got status %q for %s, want %q", got[address], address, status)
+		}
+	}
+}
+
+func TestIsTerraformOverrideFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "override.tf", want: true},
+		{name: "override.tf.json", want: true},
+		{name: "main_override.tf", want: true},
+		{name: "main_override.tf.json", want: true},
+		{name: "main.tf"},
+		{name: "nooverride.tf"},
+		{name: "dataoverride.tf.json"},
+		{name: "override.tfvars"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isTerraformOverrideFile(test.name); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package terraform
+
+import (
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// The dependency lock file only contains provider blocks, labelled with the
+// fully qualified address of the provider
+var terraformLockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "provider", LabelNames: []string{"address"}},
+	},
+}
+
+var terraformLockProviderSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "version"},
+		{Name: "constraints"},
+		{Name: "hashes"},
+	},
+}
+
+// buildTerraformLockProvider returns the row of a provider block of a
+// dependency lock file
+func buildTerraformLockProvider(path string, content []byte, block *hcl.Block) *terraformLockProvider {
+	address := block.Labels[0]
+	lockProvider := &terraformLockProvider{
+		Address:   address,
+		StartLine: block.DefRange.Start.Line,
+		EndLine:   block.DefRange.End.Line,
+		Path:      path,
+	}
+	if body, ok := block.Body.(*hclsyntax.Body); ok {
+		lockProvider.EndLine = body.SrcRange.End.Line
+	}
+	lockProvider.Hostname, lockProvider.Namespace, lockProvider.Type = parseProviderSource(address)
+
+	blockContent, _, _ := block.Body.PartialContent(terraformLockProviderSchema)
+	if attr, ok := blockContent.Attributes["version"]; ok {
+		lockProvider.Version, _ = getTerraformExpressionValue(attr.Expr, content).(string)
+	}
+	if attr, ok := blockContent.Attributes["constraints"]; ok {
+		lockProvider.Constraints, _ = getTerraformExpressionValue(attr.Expr, content).(string)
+	}
+	if attr, ok := blockContent.Attributes["hashes"]; ok {
+		exprs, _ := hcl.ExprList(attr.Expr)
+		for _, expr := range exprs {
+			hash, ok := getTerraformExpressionValue(expr, content).(string)
+			if !ok {
+				continue
+			}
+
+			// Hashes are prefixed with their scheme, e.g. h1:<hash> or zh:<hash>
+			lockProviderHash := terraformLockProviderHash{Value: hash}
+			if scheme, value, found := strings.Cut(hash, ":"); found {
+				lockProviderHash.Scheme = scheme
+				lockProviderHash.Value = value
+			}
+			lockProvider.Hashes = append(lockProvider.Hashes, lockProviderHash)
+		}
+	}
+
+	return lockProvider
+}
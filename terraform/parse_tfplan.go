@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 )
 
 type TerraformPlanResource struct {
@@ -12,21 +14,202 @@ type TerraformPlanResource struct {
 	Mode    string                 `cty:"mode"`
 	Values  map[string]interface{} `cty:"values"`
 	Address string                 `cty:"address"`
+	Index   interface{}            `json:"index"`
+	// ModuleAddress is not part of the resource object in the plan, it is
+	// populated from the address of the module containing the resource
+	ModuleAddress string `json:"-"`
+	// Raw is the resource object as found in a JSON plan
+	Raw json.RawMessage `json:"-"`
 }
 
-type TerraformPlanPlannedValuesRootModule struct {
-	Resources []TerraformPlanResource `json:"resources"`
+// UnmarshalJSON decodes a resource object, keeping a copy of its JSON encoding
+func (r *TerraformPlanResource) UnmarshalJSON(data []byte) error {
+	type planResource TerraformPlanResource
+	if err := json.Unmarshal(data, (*planResource)(r)); err != nil {
+		return err
+	}
+	r.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// TerraformPlanModule represents the root module or any of the nested child
+// modules in the planned values of a plan
+type TerraformPlanModule struct {
+	Address      string                  `json:"address"`
+	Resources    []TerraformPlanResource `json:"resources"`
+	ChildModules []TerraformPlanModule   `json:"child_modules"`
+}
+
+type TerraformPlanOutput struct {
+	Sensitive bool        `json:"sensitive"`
+	Value     interface{} `json:"value"`
 }
 
 type TerraformPlanPlannedValues struct {
-	RootModule TerraformPlanPlannedValuesRootModule `json:"root_module"`
+	Outputs    map[string]TerraformPlanOutput `json:"outputs"`
+	RootModule TerraformPlanModule            `json:"root_module"`
+}
+
+// TerraformPlanPriorState represents the state embedded in a plan, as it was
+// before the plan was created. Its values share the structure of the planned
+// values.
+type TerraformPlanPriorState struct {
+	FormatVersion    string                     `json:"format_version"`
+	TerraformVersion string                     `json:"terraform_version"`
+	Values           TerraformPlanPlannedValues `json:"values"`
+}
+
+// TerraformPlanChange describes the planned change for a single object, as
+// found in the "change" property of each resource_changes entry.
+type TerraformPlanChange struct {
+	Actions         []string    `json:"actions"`
+	Before          interface{} `json:"before"`
+	After           interface{} `json:"after"`
+	AfterUnknown    interface{} `json:"after_unknown"`
+	BeforeSensitive interface{} `json:"before_sensitive"`
+	AfterSensitive  interface{} `json:"after_sensitive"`
+	ReplacePaths    interface{} `json:"replace_paths"`
+}
+
+// TerraformPlanResourceChange represents an entry of the resource_changes or
+// the resource_drift arrays of a plan
+type TerraformPlanResourceChange struct {
+	Address         string              `json:"address"`
+	PreviousAddress string              `json:"previous_address"`
+	ModuleAddress   string              `json:"module_address"`
+	Mode            string              `json:"mode"`
+	Type            string              `json:"type"`
+	Name            string              `json:"name"`
+	Index           interface{}         `json:"index"`
+	ProviderName    string              `json:"provider_name"`
+	Deposed         string              `json:"deposed"`
+	Change          TerraformPlanChange `json:"change"`
+	ActionReason    string              `json:"action_reason"`
+}
+
+// TerraformPlanConfigurationResource represents a resource block recorded in
+// the configuration section of a plan
+type TerraformPlanConfigurationResource struct {
+	Address           string                 `json:"address"`
+	Mode              string                 `json:"mode"`
+	Type              string                 `json:"type"`
+	Name              string                 `json:"name"`
+	ProviderConfigKey string                 `json:"provider_config_key"`
+	Expressions       map[string]interface{} `json:"expressions"`
+	SchemaVersion     int                    `json:"schema_version"`
+	CountExpression   interface{}            `json:"count_expression"`
+	ForEachExpression interface{}            `json:"for_each_expression"`
+	DependsOn         []string               `json:"depends_on"`
+	// ModuleAddress is not part of the resource object in the plan, it is
+	// populated from the module calls leading to the resource
+	ModuleAddress string `json:"-"`
+}
+
+// TerraformPlanModuleCall represents a module block recorded in the
+// configuration section of a plan
+type TerraformPlanModuleCall struct {
+	Source            string                           `json:"source"`
+	VersionConstraint string                           `json:"version_constraint"`
+	Expressions       map[string]interface{}           `json:"expressions"`
+	CountExpression   interface{}                      `json:"count_expression"`
+	ForEachExpression interface{}                      `json:"for_each_expression"`
+	DependsOn         []string                         `json:"depends_on"`
+	Module            TerraformPlanConfigurationModule `json:"module"`
+	// Name, Address and ModuleAddress are not part of the module call object
+	// in the plan, they are populated from the module calls leading to it
+	Name          string `json:"-"`
+	Address       string `json:"-"`
+	ModuleAddress string `json:"-"`
+}
+
+// TerraformPlanConfigurationVariable represents a variable block recorded in
+// the configuration section of a plan
+type TerraformPlanConfigurationVariable struct {
+	Default     interface{} `json:"default"`
+	Description string      `json:"description"`
+	Sensitive   bool        `json:"sensitive"`
+}
+
+// TerraformPlanConfigurationModule represents the root module or the module
+// of any module call in the configuration section of a plan
+type TerraformPlanConfigurationModule struct {
+	Resources   []TerraformPlanConfigurationResource          `json:"resources"`
+	ModuleCalls map[string]TerraformPlanModuleCall            `json:"module_calls"`
+	Variables   map[string]TerraformPlanConfigurationVariable `json:"variables"`
+}
+
+type TerraformPlanConfiguration struct {
+	RootModule TerraformPlanConfigurationModule `json:"root_module"`
+}
+
+// TerraformPlanVariable represents the value of a root module variable used
+// to create a plan
+type TerraformPlanVariable struct {
+	Value interface{} `json:"value"`
+}
+
+// TerraformPlanCheckAddress represents the address of a checkable object in
+// the checks section of a plan
+type TerraformPlanCheckAddress struct {
+	Kind      string `json:"kind"`
+	ToDisplay string `json:"to_display"`
+}
+
+// TerraformPlanCheckProblem represents a failure message of a checkable object
+type TerraformPlanCheckProblem struct {
+	Message string `json:"message"`
+}
+
+// TerraformPlanCheckInstance represents the result of the checks of a single
+// instance of a checkable object, e.g. an instance of a resource using count
+type TerraformPlanCheckInstance struct {
+	Address  TerraformPlanCheckAddress   `json:"address"`
+	Status   string                      `json:"status"`
+	Problems []TerraformPlanCheckProblem `json:"problems"`
+}
+
+// TerraformPlanCheck represents the aggregated result of the checks of a
+// checkable object in the configuration, i.e. a resource, an output value, an
+// input variable or a check block
+type TerraformPlanCheck struct {
+	Address   TerraformPlanCheckAddress    `json:"address"`
+	Status    string                       `json:"status"`
+	Instances []TerraformPlanCheckInstance `json:"instances"`
+}
+
+// TerraformPlanResourceAttribute represents a resource attribute which may
+// have contributed to the planned changes
+type TerraformPlanResourceAttribute struct {
+	Resource  string      `json:"resource"`
+	Attribute interface{} `json:"attribute"`
 }
 
 type TerraformPlanContentStruct struct {
-	PlannedValues TerraformPlanPlannedValues `json:"planned_values"`
+	FormatVersion      string                           `json:"format_version"`
+	TerraformVersion   string                           `json:"terraform_version"`
+	Timestamp          string                           `json:"timestamp"`
+	Applyable          *bool                            `json:"applyable"`
+	Complete           *bool                            `json:"complete"`
+	Errored            *bool                            `json:"errored"`
+	RelevantAttributes []TerraformPlanResourceAttribute `json:"relevant_attributes"`
+	Variables          map[string]TerraformPlanVariable `json:"variables"`
+	PlannedValues      TerraformPlanPlannedValues       `json:"planned_values"`
+	PriorState         *TerraformPlanPriorState         `json:"prior_state"`
+	ResourceDrift      []TerraformPlanResourceChange    `json:"resource_drift"`
+	ResourceChanges    []TerraformPlanResourceChange    `json:"resource_changes"`
+	OutputChanges      map[string]TerraformPlanChange   `json:"output_changes"`
+	Configuration      TerraformPlanConfiguration       `json:"configuration"`
+	Checks             []TerraformPlanCheck             `json:"checks"`
+	// IsBinaryPlan is set if the content has been decoded from a binary plan
+	// file rather than from the JSON plan format
+	IsBinaryPlan bool `json:"-"`
 }
 
 func getTerraformPlanContentFromBytes(rawContent []byte) (*TerraformPlanContentStruct, error) {
+	if isTerraformBinaryPlan(rawContent) {
+		return getTerraformBinaryPlanContent(rawContent)
+	}
+
 	var planContent *TerraformPlanContentStruct
 	err := json.Unmarshal(rawContent, &planContent)
 	if err != nil {
@@ -35,17 +218,128 @@ func getTerraformPlanContentFromBytes(rawContent []byte) (*TerraformPlanContentS
 	return planContent, nil
 }
 
-func buildTerraformPlanResource(ctx context.Context, path string, resource TerraformPlanResource) (*terraformResource, error) {
+// getTerraformPlanModuleResources returns the resources of the given module and
+// of all its child modules, recursively
+func getTerraformPlanModuleResources(module TerraformPlanModule) []TerraformPlanResource {
+	var resources []TerraformPlanResource
+	for _, resource := range module.Resources {
+		resource.ModuleAddress = module.Address
+		resources = append(resources, resource)
+	}
+	for _, childModule := range module.ChildModules {
+		resources = append(resources, getTerraformPlanModuleResources(childModule)...)
+	}
+	return resources
+}
+
+// getTerraformPlanConfigurationResources returns the resources of the given
+// configuration module and of the modules of all its module calls, recursively
+func getTerraformPlanConfigurationResources(moduleAddress string, module TerraformPlanConfigurationModule) []TerraformPlanConfigurationResource {
+	var resources []TerraformPlanConfigurationResource
+	for _, resource := range module.Resources {
+		resource.ModuleAddress = moduleAddress
+		resources = append(resources, resource)
+	}
+	for _, moduleCall := range getTerraformPlanModuleCalls(moduleAddress, module) {
+		resources = append(resources, getTerraformPlanConfigurationResources(moduleCall.Address, moduleCall.Module)...)
+	}
+	return resources
+}
+
+// getTerraformPlanModuleCalls returns the module calls of the given
+// configuration module, without descending into the called modules
+func getTerraformPlanModuleCalls(moduleAddress string, module TerraformPlanConfigurationModule) []TerraformPlanModuleCall {
+	var moduleCalls []TerraformPlanModuleCall
+	for name, moduleCall := range module.ModuleCalls {
+		moduleCall.Name = name
+		moduleCall.ModuleAddress = moduleAddress
+		moduleCall.Address = joinModuleAddress(moduleAddress, "module."+name)
+		moduleCalls = append(moduleCalls, moduleCall)
+	}
+	return moduleCalls
+}
+
+// getAllTerraformPlanModuleCalls returns the module calls of the given
+// configuration module and of all the called modules, recursively
+func getAllTerraformPlanModuleCalls(moduleAddress string, module TerraformPlanConfigurationModule) []TerraformPlanModuleCall {
+	var moduleCalls []TerraformPlanModuleCall
+	for _, moduleCall := range getTerraformPlanModuleCalls(moduleAddress, module) {
+		moduleCalls = append(moduleCalls, moduleCall)
+		moduleCalls = append(moduleCalls, getAllTerraformPlanModuleCalls(moduleCall.Address, moduleCall.Module)...)
+	}
+	return moduleCalls
+}
+
+// joinModuleAddress prefixes an address with the address of the module it is
+// declared in, if any
+func joinModuleAddress(moduleAddress string, address string) string {
+	if moduleAddress == "" {
+		return address
+	}
+	return moduleAddress + "." + address
+}
+
+// getExpressionReferences returns the unique references found in the given
+// plan expressions. Expressions of nested blocks are either maps or lists of
+// maps of expressions, so the references are collected recursively.
+func getExpressionReferences(expressions ...interface{}) []string {
+	var references []string
+	seen := map[string]bool{}
+
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch item := v.(type) {
+		case map[string]interface{}:
+			for key, value := range item {
+				switch key {
+				case "references":
+					refs, _ := value.([]interface{})
+					for _, ref := range refs {
+						if refStr, ok := ref.(string); ok && !seen[refStr] {
+							seen[refStr] = true
+							references = append(references, refStr)
+						}
+					}
+				// Constant values are literal values, which never contain references
+				case "constant_value":
+				default:
+					collect(value)
+				}
+			}
+		case []interface{}:
+			for _, value := range item {
+				collect(value)
+			}
+		}
+	}
+
+	for _, expression := range expressions {
+		collect(expression)
+	}
+
+	sort.Strings(references)
+	return references
+}
+
+func buildTerraformPlanResource(ctx context.Context, path string, isBinaryPlan bool, resource TerraformPlanResource) (*terraformResource, error) {
 	tfResource := new(terraformResource)
 
 	tfResource.Path = path
 	tfResource.Type = resource.Type
 	tfResource.Name = resource.Name
 	tfResource.Address = resource.Address
+	tfResource.ModuleAddress = resource.ModuleAddress
+	tfResource.IndexKey = resource.Index
 	tfResource.Mode = resource.Mode
+	tfResource.PlanSection = "planned_values"
 	tfResource.Arguments = resource.Values
 	tfResource.AttributesStd = tfResource.Arguments
 
+	// The source is only available for plan files in JSON format
+	if isBinaryPlan {
+		return tfResource, nil
+	}
+
 	startLine, endLine, source, err := findBlockLinesFromJSON(ctx, path, "resources", resource.Address, resource.Type)
 	if err != nil {
 		return nil, err
@@ -57,3 +351,191 @@ func buildTerraformPlanResource(ctx context.Context, path string, resource Terra
 
 	return tfResource, nil
 }
+
+// buildTerraformPlanPriorStateResource builds a resource from the prior state
+// of a plan. The values of these resources are attributes of existing objects,
+// so they are returned as attributes rather than arguments.
+func buildTerraformPlanPriorStateResource(path string, resource TerraformPlanResource) *terraformResource {
+	tfResource := new(terraformResource)
+
+	tfResource.Path = path
+	tfResource.PlanSection = "prior_state"
+	tfResource.Type = resource.Type
+	tfResource.Name = resource.Name
+	tfResource.Address = resource.Address
+	tfResource.ModuleAddress = resource.ModuleAddress
+	tfResource.IndexKey = resource.Index
+	tfResource.Mode = resource.Mode
+	tfResource.Attributes = resource.Values
+	tfResource.AttributesStd = tfResource.Attributes
+
+	return tfResource
+}
+
+func buildTerraformPlanResourceChange(path string, change TerraformPlanResourceChange) *terraformResourceChange {
+	tfResourceChange := new(terraformResourceChange)
+
+	tfResourceChange.Path = path
+	tfResourceChange.Address = change.Address
+	tfResourceChange.PreviousAddress = change.PreviousAddress
+	tfResourceChange.ModuleAddress = change.ModuleAddress
+	tfResourceChange.Mode = change.Mode
+	tfResourceChange.Type = change.Type
+	tfResourceChange.Name = change.Name
+	tfResourceChange.Index = change.Index
+	tfResourceChange.ProviderName = change.ProviderName
+	tfResourceChange.Deposed = change.Deposed
+	tfResourceChange.ActionReason = change.ActionReason
+	tfResourceChange.Actions = change.Change.Actions
+	tfResourceChange.Before = change.Change.Before
+	tfResourceChange.After = change.Change.After
+	tfResourceChange.AfterUnknown = change.Change.AfterUnknown
+	tfResourceChange.BeforeSensitive = change.Change.BeforeSensitive
+	tfResourceChange.AfterSensitive = change.Change.AfterSensitive
+	tfResourceChange.ReplacePaths = change.Change.ReplacePaths
+
+	return tfResourceChange
+}
+
+func buildTerraformPlanOutputChange(path string, name string, change TerraformPlanChange, output TerraformPlanOutput) *terraformPlanOutputChange {
+	tfOutputChange := new(terraformPlanOutputChange)
+
+	tfOutputChange.Path = path
+	tfOutputChange.Name = name
+	// Deleted outputs have no planned value, so their sensitivity is the one
+	// of their value before the change
+	sensitivity := change.AfterSensitive
+	if len(change.Actions) == 1 && change.Actions[0] == "delete" {
+		sensitivity = change.BeforeSensitive
+	}
+	if sensitive, ok := sensitivity.(bool); ok {
+		tfOutputChange.Sensitive = sensitive
+	} else {
+		tfOutputChange.Sensitive = output.Sensitive
+	}
+	tfOutputChange.Actions = change.Actions
+	tfOutputChange.Before = change.Before
+	tfOutputChange.After = change.After
+	tfOutputChange.AfterUnknown = change.AfterUnknown
+	tfOutputChange.BeforeSensitive = change.BeforeSensitive
+	tfOutputChange.AfterSensitive = change.AfterSensitive
+
+	return tfOutputChange
+}
+
+func buildTerraformPlanConfigurationResource(path string, resource TerraformPlanConfigurationResource) *terraformPlanConfigurationResource {
+	tfResource := new(terraformPlanConfigurationResource)
+
+	tfResource.Path = path
+	tfResource.Address = joinModuleAddress(resource.ModuleAddress, resource.Address)
+	tfResource.ModuleAddress = resource.ModuleAddress
+	tfResource.Mode = resource.Mode
+	tfResource.Type = resource.Type
+	tfResource.Name = resource.Name
+	tfResource.ProviderConfigKey = resource.ProviderConfigKey
+	tfResource.Expressions = resource.Expressions
+	tfResource.SchemaVersion = resource.SchemaVersion
+	tfResource.CountExpression = resource.CountExpression
+	tfResource.ForEachExpression = resource.ForEachExpression
+	tfResource.DependsOn = resource.DependsOn
+	tfResource.References = getExpressionReferences(resource.Expressions, resource.CountExpression, resource.ForEachExpression)
+
+	return tfResource
+}
+
+func buildTerraformPlanModuleCall(path string, moduleCall TerraformPlanModuleCall) *terraformPlanModuleCall {
+	tfModuleCall := new(terraformPlanModuleCall)
+
+	tfModuleCall.Path = path
+	tfModuleCall.Name = moduleCall.Name
+	tfModuleCall.Address = moduleCall.Address
+	tfModuleCall.ModuleAddress = moduleCall.ModuleAddress
+	tfModuleCall.ModuleSource = moduleCall.Source
+	tfModuleCall.VersionConstraint = moduleCall.VersionConstraint
+	tfModuleCall.Expressions = moduleCall.Expressions
+	tfModuleCall.CountExpression = moduleCall.CountExpression
+	tfModuleCall.ForEachExpression = moduleCall.ForEachExpression
+	tfModuleCall.DependsOn = moduleCall.DependsOn
+	tfModuleCall.References = getExpressionReferences(moduleCall.Expressions, moduleCall.CountExpression, moduleCall.ForEachExpression)
+
+	return tfModuleCall
+}
+
+func buildTerraformPlan(path string, planContent *TerraformPlanContentStruct) *terraformPlan {
+	tfPlan := new(terraformPlan)
+
+	tfPlan.Path = path
+	tfPlan.FormatVersion = planContent.FormatVersion
+	tfPlan.TerraformVersion = planContent.TerraformVersion
+	tfPlan.Timestamp = planContent.Timestamp
+	tfPlan.Applyable = planContent.Applyable
+	tfPlan.Complete = planContent.Complete
+	tfPlan.Errored = planContent.Errored
+	tfPlan.RelevantAttributes = planContent.RelevantAttributes
+
+	for _, change := range planContent.ResourceChanges {
+		switch strings.Join(change.Change.Actions, ",") {
+		case "create":
+			tfPlan.CreateCount++
+		case "update":
+			tfPlan.UpdateCount++
+		case "delete":
+			tfPlan.DeleteCount++
+		case "delete,create", "create,delete":
+			tfPlan.ReplaceCount++
+		case "read":
+			tfPlan.ReadCount++
+		case "forget", "create,forget":
+			tfPlan.ForgetCount++
+		case "no-op":
+			tfPlan.NoOpCount++
+		}
+	}
+
+	return tfPlan
+}
+
+// buildTerraformPlanCheckResults returns a row for each instance of a
+// checkable object, or a single row if no instance has been checked yet
+func buildTerraformPlanCheckResults(path string, check TerraformPlanCheck) []*terraformCheckResult {
+	var results []*terraformCheckResult
+
+	for _, instance := range check.Instances {
+		result := &terraformCheckResult{
+			Kind:          check.Address.Kind,
+			ConfigAddress: check.Address.ToDisplay,
+			ConfigStatus:  check.Status,
+			ObjectAddress: instance.Address.ToDisplay,
+			Status:        instance.Status,
+			Path:          path,
+		}
+		for _, problem := range instance.Problems {
+			result.FailureMessages = append(result.FailureMessages, problem.Message)
+		}
+		results = append(results, result)
+	}
+
+	if len(results) == 0 {
+		results = append(results, &terraformCheckResult{
+			Kind:          check.Address.Kind,
+			ConfigAddress: check.Address.ToDisplay,
+			ConfigStatus:  check.Status,
+			Status:        check.Status,
+			Path:          path,
+		})
+	}
+
+	return results
+}
+
+func buildTerraformPlanVariable(path string, name string, variable TerraformPlanVariable, config TerraformPlanConfigurationVariable) *terraformPlanVariable {
+	tfVariable := new(terraformPlanVariable)
+
+	tfVariable.Path = path
+	tfVariable.Name = name
+	tfVariable.Description = config.Description
+	tfVariable.Sensitive = config.Sensitive
+	tfVariable.Value = variable.Value
+
+	return tfVariable
+}
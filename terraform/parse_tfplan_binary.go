@@ -0,0 +1,931 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Binary plan files, as created by "terraform plan -out", are zip archives
+// containing the plan itself in protobuf format (tfplan), the prior state
+// (tfstate) and a snapshot of the configuration (tfconfig/).
+//
+// The protobuf format is internal to Terraform, so only the fields that have
+// been stable across Terraform versions are decoded. Object values are encoded
+// in msgpack using the provider schemas, which are not available here, so
+// they are decoded as generic JSON values.
+const (
+	binaryPlanEntryName  = "tfplan"
+	binaryStateEntryName = "tfstate"
+)
+
+// Field numbers of the Plan message
+const (
+	planFieldVariables          protowire.Number = 2
+	planFieldResourceChanges    protowire.Number = 3
+	planFieldOutputChanges      protowire.Number = 4
+	planFieldTerraformVersion   protowire.Number = 14
+	planFieldRelevantAttributes protowire.Number = 15
+	planFieldResourceDrift      protowire.Number = 18
+	planFieldCheckResults       protowire.Number = 19
+	planFieldErrored            protowire.Number = 20
+	planFieldTimestamp          protowire.Number = 21
+	planFieldApplyable          protowire.Number = 25
+	planFieldComplete           protowire.Number = 26
+)
+
+// Field numbers of the entries of map fields
+const (
+	mapEntryFieldKey   protowire.Number = 1
+	mapEntryFieldValue protowire.Number = 2
+)
+
+// Field numbers of the ResourceInstanceChange message
+const (
+	resourceChangeFieldDeposedKey      protowire.Number = 7
+	resourceChangeFieldProvider        protowire.Number = 8
+	resourceChangeFieldChange          protowire.Number = 9
+	resourceChangeFieldRequiredReplace protowire.Number = 11
+	resourceChangeFieldActionReason    protowire.Number = 12
+	resourceChangeFieldAddr            protowire.Number = 13
+	resourceChangeFieldPrevRunAddr     protowire.Number = 14
+)
+
+// Field numbers of the OutputChange message
+const (
+	outputChangeFieldName      protowire.Number = 1
+	outputChangeFieldChange    protowire.Number = 2
+	outputChangeFieldSensitive protowire.Number = 3
+)
+
+// Field numbers of the Change message
+const (
+	changeFieldAction               protowire.Number = 1
+	changeFieldValues               protowire.Number = 2
+	changeFieldBeforeSensitivePaths protowire.Number = 3
+	changeFieldAfterSensitivePaths  protowire.Number = 4
+)
+
+// Field numbers of the DynamicValue, Path and Path.Step messages
+const (
+	dynamicValueFieldMsgpack   protowire.Number = 1
+	pathFieldSteps             protowire.Number = 1
+	pathStepFieldAttributeName protowire.Number = 1
+	pathStepFieldElementKey    protowire.Number = 2
+)
+
+// Field numbers of the Plan.resource_attr message
+const (
+	resourceAttrFieldResource protowire.Number = 1
+	resourceAttrFieldAttr     protowire.Number = 2
+)
+
+// The Terraform versions which introduced the boolean fields of the Plan
+// message. Proto3 omits fields set to false, so the fields are only reported
+// for plans created by Terraform versions that know about them.
+var (
+	binaryPlanErroredVersion   = version.Must(version.NewVersion("1.4.0"))
+	binaryPlanApplyableVersion = version.Must(version.NewVersion("1.8.0"))
+)
+
+// Field numbers of the CheckResults and CheckResults.ObjectResult messages
+const (
+	checkResultsFieldKind           protowire.Number = 1
+	checkResultsFieldConfigAddr     protowire.Number = 2
+	checkResultsFieldStatus         protowire.Number = 3
+	checkResultsFieldObjects        protowire.Number = 4
+	checkObjectFieldObjectAddr      protowire.Number = 1
+	checkObjectFieldStatus          protowire.Number = 2
+	checkObjectFieldFailureMessages protowire.Number = 3
+)
+
+// The actions of the Action enum, in the format of the JSON plan
+var binaryPlanActions = map[uint64][]string{
+	0: {"no-op"},
+	1: {"create"},
+	2: {"read"},
+	3: {"update"},
+	5: {"delete"},
+	6: {"delete", "create"},
+	7: {"create", "delete"},
+	8: {"forget"},
+	9: {"create", "forget"},
+}
+
+// The reasons of the ResourceInstanceActionReason enum, in the format of the
+// JSON plan
+var binaryPlanActionReasons = map[uint64]string{
+	1:  "replace_because_tainted",
+	2:  "replace_by_request",
+	3:  "replace_because_cannot_update",
+	4:  "delete_because_no_resource_config",
+	5:  "delete_because_wrong_repetition",
+	6:  "delete_because_count_index",
+	7:  "delete_because_each_key",
+	8:  "delete_because_no_module",
+	9:  "replace_by_triggers",
+	10: "read_because_config_unknown",
+	11: "read_because_dependency_pending",
+	12: "delete_because_no_move_target",
+	13: "read_because_check_nested",
+}
+
+// The kinds of the CheckResults.ObjectKind enum, in the format of the JSON plan
+var binaryPlanCheckKinds = map[uint64]string{
+	1: "resource",
+	2: "output_value",
+	3: "check",
+	4: "var",
+}
+
+// The statuses of the CheckResults.Status enum, in the format of the JSON plan
+var binaryPlanCheckStatuses = map[uint64]string{
+	0: "unknown",
+	1: "pass",
+	2: "fail",
+	3: "error",
+}
+
+// terraformBinaryPlanState is the subset of the state format (version 4)
+// required to build the prior state of a binary plan
+type terraformBinaryPlanState struct {
+	TerraformVersion string `json:"terraform_version"`
+	Resources        []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   interface{}            `json:"index_key"`
+			Deposed    string                 `json:"deposed"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+func isTerraformBinaryPlan(content []byte) bool {
+	// Zip archives start with a local file header signature
+	if !bytes.HasPrefix(content, []byte("PK\x03\x04")) {
+		return false
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return false
+	}
+	for _, file := range archive.File {
+		if file.Name == binaryPlanEntryName {
+			return true
+		}
+	}
+	return false
+}
+
+// getTerraformBinaryPlanContent decodes a binary plan file into the structure
+// of the JSON plan format, so that binary plans are handled as any other plan
+func getTerraformBinaryPlanContent(content []byte) (*TerraformPlanContentStruct, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the binary plan file: %v", err)
+	}
+
+	planContent := &TerraformPlanContentStruct{IsBinaryPlan: true}
+
+	configFiles := map[string][]byte{}
+	for _, file := range archive.File {
+		if strings.HasPrefix(file.Name, binaryConfigDirName) {
+			raw, err := readZipFile(file)
+			if err != nil {
+				return nil, err
+			}
+			configFiles[file.Name] = raw
+			continue
+		}
+
+		switch file.Name {
+		case binaryPlanEntryName:
+			raw, err := readZipFile(file)
+			if err != nil {
+				return nil, err
+			}
+			err = decodeBinaryPlan(raw, planContent)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode the binary plan file content: %v", err)
+			}
+
+		case binaryStateEntryName:
+			raw, err := readZipFile(file)
+			if err != nil {
+				return nil, err
+			}
+			priorState, err := decodeBinaryPlanState(raw)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode the prior state of the binary plan file: %v", err)
+			}
+			planContent.PriorState = priorState
+		}
+	}
+
+	planContent.PlannedValues = buildBinaryPlanPlannedValues(planContent)
+
+	configuration, err := decodeBinaryPlanConfiguration(configFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the configuration of the binary plan file: %v", err)
+	}
+	planContent.Configuration = configuration
+
+	// Binary plans only record the variables set by the caller, while JSON
+	// plans also list the default values of the root module variables
+	for name, variable := range configuration.RootModule.Variables {
+		if _, ok := planContent.Variables[name]; !ok && variable.Default != nil {
+			planContent.Variables[name] = TerraformPlanVariable{Value: variable.Default}
+		}
+	}
+
+	return planContent, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s in the binary plan file: %v", file.Name, err)
+	}
+	defer reader.Close()
+
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s in the binary plan file: %v", file.Name, err)
+	}
+	return raw, nil
+}
+
+func decodeBinaryPlan(raw []byte, planContent *TerraformPlanContentStruct) error {
+	planContent.Variables = map[string]TerraformPlanVariable{}
+	planContent.OutputChanges = map[string]TerraformPlanChange{}
+
+	var applyable, complete, errored bool
+	err := forEachProtoField(raw, func(num protowire.Number, v uint64, data []byte) error {
+		switch num {
+		case planFieldTerraformVersion:
+			planContent.TerraformVersion = string(data)
+		case planFieldTimestamp:
+			planContent.Timestamp = string(data)
+		case planFieldApplyable:
+			applyable = v != 0
+		case planFieldComplete:
+			complete = v != 0
+		case planFieldErrored:
+			errored = v != 0
+
+		case planFieldRelevantAttributes:
+			attribute, err := decodeBinaryPlanResourceAttribute(data)
+			if err != nil {
+				return err
+			}
+			planContent.RelevantAttributes = append(planContent.RelevantAttributes, attribute)
+
+		case planFieldVariables:
+			name, variable, err := decodeBinaryPlanVariable(data)
+			if err != nil {
+				return err
+			}
+			planContent.Variables[name] = variable
+
+		case planFieldResourceChanges, planFieldResourceDrift:
+			change, err := decodeBinaryPlanResourceChange(data)
+			if err != nil {
+				return err
+			}
+			if num == planFieldResourceChanges {
+				planContent.ResourceChanges = append(planContent.ResourceChanges, change)
+			} else {
+				planContent.ResourceDrift = append(planContent.ResourceDrift, change)
+			}
+
+		case planFieldOutputChanges:
+			name, change, err := decodeBinaryPlanOutputChange(data)
+			if err != nil {
+				return err
+			}
+			planContent.OutputChanges[name] = change
+
+		case planFieldCheckResults:
+			check, err := decodeBinaryPlanCheckResults(data)
+			if err != nil {
+				return err
+			}
+			planContent.Checks = append(planContent.Checks, check)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if terraformVersion, err := version.NewVersion(planContent.TerraformVersion); err == nil {
+		if terraformVersion.GreaterThanOrEqual(binaryPlanErroredVersion) {
+			planContent.Errored = &errored
+		}
+		if terraformVersion.GreaterThanOrEqual(binaryPlanApplyableVersion) {
+			planContent.Applyable = &applyable
+			planContent.Complete = &complete
+		}
+	}
+	return nil
+}
+
+func decodeBinaryPlanResourceAttribute(raw []byte) (TerraformPlanResourceAttribute, error) {
+	var attribute TerraformPlanResourceAttribute
+
+	err := forEachProtoField(raw, func(num protowire.Number, _ uint64, data []byte) error {
+		switch num {
+		case resourceAttrFieldResource:
+			attribute.Resource = string(data)
+		case resourceAttrFieldAttr:
+			path, err := decodeBinaryPlanPath(data)
+			if err != nil {
+				return err
+			}
+			attribute.Attribute = path
+		}
+		return nil
+	})
+	if err != nil {
+		return attribute, fmt.Errorf("failed to decode relevant attribute: %v", err)
+	}
+	return attribute, nil
+}
+
+func decodeBinaryPlanVariable(raw []byte) (string, TerraformPlanVariable, error) {
+	var name string
+	var variable TerraformPlanVariable
+
+	err := forEachProtoField(raw, func(num protowire.Number, _ uint64, data []byte) error {
+		switch num {
+		case mapEntryFieldKey:
+			name = string(data)
+		case mapEntryFieldValue:
+			value, _, err := decodeBinaryPlanDynamicValue(data)
+			if err != nil {
+				return err
+			}
+			variable.Value = value
+		}
+		return nil
+	})
+	if err != nil {
+		return "", variable, fmt.Errorf("failed to decode variable: %v", err)
+	}
+	return name, variable, nil
+}
+
+func decodeBinaryPlanResourceChange(raw []byte) (TerraformPlanResourceChange, error) {
+	var resourceChange TerraformPlanResourceChange
+	var changeData []byte
+	var replacePaths []interface{}
+
+	err := forEachProtoField(raw, func(num protowire.Number, v uint64, data []byte) error {
+		switch num {
+		case resourceChangeFieldAddr:
+			resourceChange.Address = string(data)
+		case resourceChangeFieldPrevRunAddr:
+			resourceChange.PreviousAddress = string(data)
+		case resourceChangeFieldDeposedKey:
+			resourceChange.Deposed = string(data)
+		case resourceChangeFieldProvider:
+			resourceChange.ProviderName = string(data)
+			if _, source, _, err := parseProviderConfigAddress(string(data)); err == nil {
+				resourceChange.ProviderName = source
+			}
+		case resourceChangeFieldChange:
+			changeData = data
+		case resourceChangeFieldRequiredReplace:
+			path, err := decodeBinaryPlanPath(data)
+			if err != nil {
+				return err
+			}
+			replacePaths = append(replacePaths, path)
+		case resourceChangeFieldActionReason:
+			resourceChange.ActionReason = binaryPlanActionReasons[v]
+		}
+		return nil
+	})
+	if err != nil {
+		return resourceChange, err
+	}
+
+	// The previous address is only relevant if the resource has been moved
+	if resourceChange.PreviousAddress == resourceChange.Address {
+		resourceChange.PreviousAddress = ""
+	}
+
+	resourceChange.ModuleAddress, resourceChange.Mode, resourceChange.Type, resourceChange.Name, resourceChange.Index, err = parseResourceInstanceAddress(resourceChange.Address)
+	if err != nil {
+		return resourceChange, err
+	}
+
+	resourceChange.Change, err = decodeBinaryPlanChange(changeData)
+	if err != nil {
+		return resourceChange, fmt.Errorf("failed to decode the change of %s: %v", resourceChange.Address, err)
+	}
+
+	// Objects without any unknown value have an empty map as unknown marker
+	if _, isMap := resourceChange.Change.AfterUnknown.(map[string]interface{}); !isMap {
+		resourceChange.Change.AfterUnknown = map[string]interface{}{}
+	}
+	if len(replacePaths) > 0 {
+		resourceChange.Change.ReplacePaths = replacePaths
+	}
+
+	return resourceChange, nil
+}
+
+func decodeBinaryPlanOutputChange(raw []byte) (string, TerraformPlanChange, error) {
+	var name string
+	var changeData []byte
+	var sensitive bool
+
+	err := forEachProtoField(raw, func(num protowire.Number, v uint64, data []byte) error {
+		switch num {
+		case outputChangeFieldName:
+			name = string(data)
+		case outputChangeFieldChange:
+			changeData = data
+		case outputChangeFieldSensitive:
+			sensitive = v != 0
+		}
+		return nil
+	})
+	if err != nil {
+		return name, TerraformPlanChange{}, err
+	}
+
+	change, err := decodeBinaryPlanChange(changeData)
+	if err != nil {
+		return name, change, fmt.Errorf("failed to decode the change of output %s: %v", name, err)
+	}
+	change.BeforeSensitive = sensitive
+	change.AfterSensitive = sensitive
+
+	// The unknown marker of an output is a single boolean
+	if change.AfterUnknown == nil {
+		change.AfterUnknown = false
+	}
+
+	return name, change, nil
+}
+
+// decodeBinaryPlanCheckResults decodes a CheckResults message, i.e., the
+// status of a checkable object and of each of its instances
+func decodeBinaryPlanCheckResults(raw []byte) (TerraformPlanCheck, error) {
+	var check TerraformPlanCheck
+
+	err := forEachProtoField(raw, func(num protowire.Number, v uint64, data []byte) error {
+		switch num {
+		case checkResultsFieldKind:
+			check.Address.Kind = binaryPlanCheckKinds[v]
+		case checkResultsFieldConfigAddr:
+			check.Address.ToDisplay = string(data)
+		case checkResultsFieldStatus:
+			check.Status = binaryPlanCheckStatuses[v]
+		case checkResultsFieldObjects:
+			instance := TerraformPlanCheckInstance{Status: binaryPlanCheckStatuses[0]}
+			err := forEachProtoField(data, func(num protowire.Number, v uint64, data []byte) error {
+				switch num {
+				case checkObjectFieldObjectAddr:
+					instance.Address.ToDisplay = string(data)
+				case checkObjectFieldStatus:
+					instance.Status = binaryPlanCheckStatuses[v]
+				case checkObjectFieldFailureMessages:
+					instance.Problems = append(instance.Problems, TerraformPlanCheckProblem{Message: string(data)})
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			check.Instances = append(check.Instances, instance)
+		}
+		return nil
+	})
+	if err != nil {
+		return check, fmt.Errorf("failed to decode check results: %v", err)
+	}
+
+	// Proto3 omits enum fields with the default value
+	if check.Status == "" {
+		check.Status = binaryPlanCheckStatuses[0]
+	}
+	return check, nil
+}
+
+// decodeBinaryPlanChange decodes a Change message
+func decodeBinaryPlanChange(raw []byte) (TerraformPlanChange, error) {
+	var change TerraformPlanChange
+	var action uint64
+	var values [][]byte
+	var beforeSensitivePaths, afterSensitivePaths [][]interface{}
+
+	err := forEachProtoField(raw, func(num protowire.Number, v uint64, data []byte) error {
+		switch num {
+		case changeFieldAction:
+			action = v
+		case changeFieldValues:
+			values = append(values, data)
+		case changeFieldBeforeSensitivePaths, changeFieldAfterSensitivePaths:
+			path, err := decodeBinaryPlanPath(data)
+			if err != nil {
+				return err
+			}
+			if num == changeFieldBeforeSensitivePaths {
+				beforeSensitivePaths = append(beforeSensitivePaths, path)
+			} else {
+				afterSensitivePaths = append(afterSensitivePaths, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return change, err
+	}
+
+	change.Actions = binaryPlanActions[action]
+
+	var decoded []interface{}
+	var unknowns []interface{}
+	for _, value := range values {
+		v, unknown, err := decodeBinaryPlanDynamicValue(value)
+		if err != nil {
+			return change, err
+		}
+		decoded = append(decoded, v)
+		unknowns = append(unknowns, unknown)
+	}
+
+	// The values hold the before and after values, except for the actions
+	// where only one of them is relevant
+	switch {
+	case len(decoded) == 1 && action == 1: // create
+		change.After, change.AfterUnknown = decoded[0], unknowns[0]
+	case len(decoded) == 1 && action == 0: // no-op
+		change.Before, change.After, change.AfterUnknown = decoded[0], decoded[0], unknowns[0]
+	case len(decoded) == 1: // delete, forget
+		change.Before = decoded[0]
+	case len(decoded) == 2:
+		change.Before, change.After, change.AfterUnknown = decoded[0], decoded[1], unknowns[1]
+	}
+
+	change.BeforeSensitive = buildSensitiveValue(change.Before, beforeSensitivePaths)
+	change.AfterSensitive = buildSensitiveValue(change.After, afterSensitivePaths)
+
+	return change, nil
+}
+
+// decodeBinaryPlanDynamicValue decodes a DynamicValue message, returning the
+// value along with its unknown marker
+func decodeBinaryPlanDynamicValue(raw []byte) (interface{}, interface{}, error) {
+	var encoded []byte
+	err := forEachProtoField(raw, func(num protowire.Number, _ uint64, data []byte) error {
+		if num == dynamicValueFieldMsgpack {
+			encoded = data
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return decodeMsgpackValue(msgpack.NewDecoder(bytes.NewReader(encoded)))
+}
+
+// decodeBinaryPlanPath decodes a Path message into the list of its steps, in
+// the format of the replace_paths of the JSON plan
+func decodeBinaryPlanPath(raw []byte) ([]interface{}, error) {
+	var steps []interface{}
+	err := forEachProtoField(raw, func(num protowire.Number, _ uint64, data []byte) error {
+		if num != pathFieldSteps {
+			return nil
+		}
+		return forEachProtoField(data, func(num protowire.Number, _ uint64, data []byte) error {
+			switch num {
+			case pathStepFieldAttributeName:
+				steps = append(steps, string(data))
+			case pathStepFieldElementKey:
+				key, _, err := decodeBinaryPlanDynamicValue(data)
+				if err != nil {
+					return err
+				}
+				steps = append(steps, key)
+			}
+			return nil
+		})
+	})
+	return steps, err
+}
+
+// decodeBinaryPlanState builds the prior state of a binary plan from the
+// state file stored in the plan
+func decodeBinaryPlanState(raw []byte) (*TerraformPlanPriorState, error) {
+	var state terraformBinaryPlanState
+	err := json.Unmarshal(raw, &state)
+	if err != nil {
+		return nil, err
+	}
+
+	priorState := &TerraformPlanPriorState{TerraformVersion: state.TerraformVersion}
+
+	// Group the resources by module, the nesting of the modules is not
+	// relevant when listing the resources
+	modules := map[string]*TerraformPlanModule{}
+	var moduleAddresses []string
+	for _, resource := range state.Resources {
+		for _, instance := range resource.Instances {
+			// Deposed objects are not part of the prior state values
+			if instance.Deposed != "" {
+				continue
+			}
+			if modules[resource.Module] == nil {
+				modules[resource.Module] = &TerraformPlanModule{Address: resource.Module}
+				moduleAddresses = append(moduleAddresses, resource.Module)
+			}
+			modules[resource.Module].Resources = append(modules[resource.Module].Resources, TerraformPlanResource{
+				Address: buildResourceInstanceAddress(resource.Module, resource.Mode, resource.Type, resource.Name, instance.IndexKey),
+				Mode:    resource.Mode,
+				Type:    resource.Type,
+				Name:    resource.Name,
+				Index:   instance.IndexKey,
+				Values:  instance.Attributes,
+			})
+		}
+	}
+
+	for _, address := range moduleAddresses {
+		if address == "" {
+			priorState.Values.RootModule.Resources = modules[address].Resources
+		} else {
+			priorState.Values.RootModule.ChildModules = append(priorState.Values.RootModule.ChildModules, *modules[address])
+		}
+	}
+
+	return priorState, nil
+}
+
+// buildBinaryPlanPlannedValues builds the planned values of a binary plan from
+// the after values of its changes. Resources without any change, e.g. in
+// refresh-only plans, keep the values of the prior state.
+func buildBinaryPlanPlannedValues(planContent *TerraformPlanContentStruct) TerraformPlanPlannedValues {
+	var plannedValues TerraformPlanPlannedValues
+
+	modules := map[string]*TerraformPlanModule{}
+	var moduleAddresses []string
+	addResource := func(moduleAddress string, resource TerraformPlanResource) {
+		if modules[moduleAddress] == nil {
+			modules[moduleAddress] = &TerraformPlanModule{Address: moduleAddress}
+			moduleAddresses = append(moduleAddresses, moduleAddress)
+		}
+		modules[moduleAddress].Resources = append(modules[moduleAddress].Resources, resource)
+	}
+
+	changed := map[string]bool{}
+	for _, change := range planContent.ResourceChanges {
+		changed[change.Address] = true
+
+		// Deleted and deposed objects are not part of the planned values
+		if change.Deposed != "" || change.Change.After == nil {
+			continue
+		}
+		values, _ := change.Change.After.(map[string]interface{})
+		addResource(change.ModuleAddress, TerraformPlanResource{
+			Address: change.Address,
+			Mode:    change.Mode,
+			Type:    change.Type,
+			Name:    change.Name,
+			Index:   change.Index,
+			Values:  values,
+		})
+	}
+
+	if planContent.PriorState != nil {
+		for _, resource := range getTerraformPlanModuleResources(planContent.PriorState.Values.RootModule) {
+			if !changed[resource.Address] {
+				addResource(resource.ModuleAddress, resource)
+			}
+		}
+	}
+
+	for _, address := range moduleAddresses {
+		if address == "" {
+			plannedValues.RootModule.Resources = modules[address].Resources
+		} else {
+			plannedValues.RootModule.ChildModules = append(plannedValues.RootModule.ChildModules, *modules[address])
+		}
+	}
+
+	plannedValues.Outputs = map[string]TerraformPlanOutput{}
+	for name, change := range planContent.OutputChanges {
+		if change.Actions != nil && change.Actions[0] == "delete" {
+			continue
+		}
+		sensitive, _ := change.AfterSensitive.(bool)
+		plannedValues.Outputs[name] = TerraformPlanOutput{
+			Sensitive: sensitive,
+			Value:     change.After,
+		}
+	}
+
+	return plannedValues
+}
+
+// forEachProtoField calls fn for each field of a protobuf message, passing the
+// value of varint fields as v and the value of length-delimited fields as data
+func forEachProtoField(raw []byte, fn func(num protowire.Number, v uint64, data []byte) error) error {
+	for len(raw) > 0 {
+		num, typ, n := protowire.ConsumeTag(raw)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		var v uint64
+		var data []byte
+		switch typ {
+		case protowire.VarintType:
+			v, n = protowire.ConsumeVarint(raw)
+		case protowire.BytesType:
+			data, n = protowire.ConsumeBytes(raw)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, raw)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		raw = raw[n:]
+
+		err := fn(num, v, data)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeMsgpackValue decodes the next msgpack encoded value to its JSON
+// representation, omitting unknown values. The second value returned marks the
+// unknown values in the same structure as the after_unknown property of the
+// JSON plan, or is false if the value is wholly known.
+//
+// Values of dynamic types are encoded as a pair of their type, as JSON bytes,
+// and their value. Binary data is not used for any other purpose, so these are
+// unwrapped to their value.
+func decodeMsgpackValue(dec *msgpack.Decoder) (interface{}, interface{}, error) {
+	code, err := dec.PeekCode()
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case code == msgpcode.Nil:
+		return nil, false, dec.Skip()
+
+	// Extensions are only used for unknown values
+	case msgpcode.IsExt(code):
+		return nil, true, dec.Skip()
+
+	case code == msgpcode.True || code == msgpcode.False:
+		v, err := dec.DecodeBool()
+		return v, false, err
+
+	case msgpcode.IsFixedNum(code) || code == msgpcode.Int8 || code == msgpcode.Int16 || code == msgpcode.Int32 || code == msgpcode.Int64 ||
+		code == msgpcode.Uint8 || code == msgpcode.Uint16 || code == msgpcode.Uint32 || code == msgpcode.Uint64 ||
+		code == msgpcode.Float || code == msgpcode.Double:
+		v, err := dec.DecodeFloat64()
+		return v, false, err
+
+	case msgpcode.IsString(code):
+		v, err := dec.DecodeString()
+		return v, false, err
+
+	case msgpcode.IsFixedMap(code) || code == msgpcode.Map16 || code == msgpcode.Map32:
+		l, err := dec.DecodeMapLen()
+		if err != nil {
+			return nil, false, err
+		}
+		result := map[string]interface{}{}
+		unknowns := map[string]interface{}{}
+		for i := 0; i < l; i++ {
+			k, err := dec.DecodeString()
+			if err != nil {
+				return nil, false, err
+			}
+			v, unknown, err := decodeMsgpackValue(dec)
+			if err != nil {
+				return nil, false, err
+			}
+			if unknown == true {
+				unknowns[k] = unknown
+				continue
+			}
+			if unknown != false {
+				unknowns[k] = unknown
+			}
+			result[k] = v
+		}
+		if len(unknowns) == 0 {
+			return result, false, nil
+		}
+		return result, unknowns, nil
+
+	case msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32:
+		l, err := dec.DecodeArrayLen()
+		if err != nil {
+			return nil, false, err
+		}
+
+		// Unwrap values of dynamic types
+		if l == 2 {
+			next, err := dec.PeekCode()
+			if err != nil {
+				return nil, false, err
+			}
+			if msgpcode.IsBin(next) {
+				if err := dec.Skip(); err != nil {
+					return nil, false, err
+				}
+				return decodeMsgpackValue(dec)
+			}
+		}
+
+		result := []interface{}{}
+		unknowns := []interface{}{}
+		hasUnknown := false
+		for i := 0; i < l; i++ {
+			v, unknown, err := decodeMsgpackValue(dec)
+			if err != nil {
+				return nil, false, err
+			}
+			if unknown != false {
+				hasUnknown = true
+			}
+			result = append(result, v)
+			unknowns = append(unknowns, unknown)
+		}
+		if !hasUnknown {
+			return result, false, nil
+		}
+		return result, unknowns, nil
+	}
+
+	return nil, false, fmt.Errorf("unsupported msgpack code %#x", code)
+}
+
+// buildSensitiveValue marks the sensitive paths of a value in the same
+// structure as the before_sensitive and after_sensitive properties of the JSON
+// plan
+func buildSensitiveValue(value interface{}, paths [][]interface{}) interface{} {
+	if _, isMap := value.(map[string]interface{}); !isMap {
+		return len(paths) > 0
+	}
+
+	result := map[string]interface{}{}
+	for _, path := range paths {
+		if len(path) == 0 {
+			return true
+		}
+		if m, ok := setSensitivePath(result, path).(map[string]interface{}); ok {
+			result = m
+		}
+	}
+	return result
+}
+
+func setSensitivePath(node interface{}, path []interface{}) interface{} {
+	if len(path) == 0 {
+		return true
+	}
+
+	switch step := path[0].(type) {
+	case string:
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			m = map[string]interface{}{}
+		}
+		m[step] = setSensitivePath(m[step], path[1:])
+		return m
+	case float64:
+		l, _ := node.([]interface{})
+		for len(l) <= int(step) {
+			l = append(l, false)
+		}
+		l[int(step)] = setSensitivePath(l[int(step)], path[1:])
+		return l
+	}
+	return node
+}
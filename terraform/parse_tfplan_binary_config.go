@@ -0,0 +1,404 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// The configuration snapshot of a binary plan stores the files of each module
+// in a tfconfig/m-<module key>/ directory, and the list of modules in
+// tfconfig/modules.json. The configuration section of the JSON plan is
+// rebuilt from the snapshot, without the provider schemas, so nested blocks
+// are always represented as lists of expressions.
+const (
+	binaryConfigDirName      = "tfconfig/"
+	binaryConfigManifestName = "tfconfig/modules.json"
+)
+
+// terraformBinaryPlanModule is an entry of the modules.json manifest of the
+// configuration snapshot
+type terraformBinaryPlanModule struct {
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version"`
+	Dir     string `json:"Dir"`
+}
+
+// Meta-arguments of resource and module blocks, which are not part of the
+// expressions of the block
+var (
+	binaryConfigResourceMetaAttributes = map[string]bool{"count": true, "for_each": true, "provider": true, "depends_on": true}
+	binaryConfigResourceMetaBlocks     = map[string]bool{"lifecycle": true, "provisioner": true, "connection": true, "dynamic": true}
+	binaryConfigModuleMetaAttributes   = map[string]bool{"source": true, "version": true, "count": true, "for_each": true, "providers": true, "depends_on": true}
+)
+
+var binaryConfigModuleSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "variable", LabelNames: []string{"name"}},
+	},
+}
+
+// binaryPlanConfigModule holds the parsed files of a module of the
+// configuration snapshot
+type binaryPlanConfigModule struct {
+	key    string
+	blocks hcl.Blocks
+}
+
+// decodeBinaryPlanConfiguration builds the configuration section of a binary
+// plan from the files of its configuration snapshot, indexed by name
+func decodeBinaryPlanConfiguration(files map[string][]byte) (TerraformPlanConfiguration, error) {
+	var configuration TerraformPlanConfiguration
+
+	manifest, ok := files[binaryConfigManifestName]
+	if !ok {
+		return configuration, nil
+	}
+	var modules []terraformBinaryPlanModule
+	if err := json.Unmarshal(manifest, &modules); err != nil {
+		return configuration, fmt.Errorf("failed to decode the modules manifest: %v", err)
+	}
+
+	// Sort the file names so that blocks are read in a stable order
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parser := hclparse.NewParser()
+	parsed := map[string]*binaryPlanConfigModule{}
+	for _, module := range modules {
+		configModule := &binaryPlanConfigModule{key: module.Key}
+		dir := binaryConfigDirName + "m-" + module.Key + "/"
+		for _, name := range names {
+			// Override files are merged into the primary files by Terraform,
+			// which is not supported here
+			base := path.Base(name)
+			if path.Dir(name)+"/" != dir || !(strings.HasSuffix(base, ".tf") || strings.HasSuffix(base, ".tf.json")) || isTerraformOverrideFile(base) {
+				continue
+			}
+			file, diags := parseTerraformConfigFile(parser, name, files[name])
+			if diags.HasErrors() {
+				return configuration, fmt.Errorf("failed to parse %s: %v", name, diags.Error())
+			}
+			content, _, _ := file.Body.PartialContent(binaryConfigModuleSchema)
+			configModule.blocks = append(configModule.blocks, content.Blocks...)
+		}
+		parsed[module.Key] = configModule
+	}
+
+	if root, ok := parsed[""]; ok {
+		configuration.RootModule = buildBinaryPlanConfigurationModule(root, parsed)
+	}
+	return configuration, nil
+}
+
+func buildBinaryPlanConfigurationModule(module *binaryPlanConfigModule, parsed map[string]*binaryPlanConfigModule) TerraformPlanConfigurationModule {
+	var configModule TerraformPlanConfigurationModule
+
+	for _, block := range module.blocks {
+		switch block.Type {
+		case "resource", "data":
+			configModule.Resources = append(configModule.Resources, buildBinaryPlanConfigurationResource(module, parsed, block))
+
+		case "module":
+			if configModule.ModuleCalls == nil {
+				configModule.ModuleCalls = map[string]TerraformPlanModuleCall{}
+			}
+			name := block.Labels[0]
+			moduleCall := TerraformPlanModuleCall{
+				Expressions: buildBinaryPlanExpressions(block.Body, binaryConfigModuleMetaAttributes, nil),
+			}
+			attributes, _ := block.Body.JustAttributes()
+			if attr, ok := attributes["source"]; ok {
+				if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && value.IsKnown() {
+					moduleCall.Source = value.AsString()
+				}
+			}
+			if attr, ok := attributes["version"]; ok {
+				if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && value.IsKnown() {
+					moduleCall.VersionConstraint = value.AsString()
+				}
+			}
+			moduleCall.CountExpression, moduleCall.ForEachExpression = buildBinaryPlanRepetitionExpressions(attributes)
+			moduleCall.DependsOn = buildBinaryPlanDependsOn(attributes)
+
+			childKey := name
+			if module.key != "" {
+				childKey = module.key + "." + name
+			}
+			if child, ok := parsed[childKey]; ok {
+				moduleCall.Module = buildBinaryPlanConfigurationModule(child, parsed)
+			}
+			configModule.ModuleCalls[name] = moduleCall
+
+		case "variable":
+			if configModule.Variables == nil {
+				configModule.Variables = map[string]TerraformPlanConfigurationVariable{}
+			}
+			configModule.Variables[block.Labels[0]] = buildBinaryPlanConfigurationVariable(block)
+		}
+	}
+
+	sort.Slice(configModule.Resources, func(i, j int) bool {
+		return configModule.Resources[i].Address < configModule.Resources[j].Address
+	})
+	return configModule
+}
+
+func buildBinaryPlanConfigurationResource(module *binaryPlanConfigModule, parsed map[string]*binaryPlanConfigModule, block *hcl.Block) TerraformPlanConfigurationResource {
+	resource := TerraformPlanConfigurationResource{
+		Mode:        "managed",
+		Type:        block.Labels[0],
+		Name:        block.Labels[1],
+		Expressions: buildBinaryPlanExpressions(block.Body, binaryConfigResourceMetaAttributes, binaryConfigResourceMetaBlocks),
+	}
+	resource.Address = resource.Type + "." + resource.Name
+	if block.Type == "data" {
+		resource.Mode = "data"
+		resource.Address = "data." + resource.Address
+	}
+
+	attributes, _ := block.Body.JustAttributes()
+	resource.CountExpression, resource.ForEachExpression = buildBinaryPlanRepetitionExpressions(attributes)
+	resource.DependsOn = buildBinaryPlanDependsOn(attributes)
+
+	// The provider configuration defaults to the provider named after the
+	// prefix of the resource type
+	provider := resource.Type
+	if i := strings.Index(provider, "_"); i != -1 {
+		provider = provider[:i]
+	}
+	if attr, ok := attributes["provider"]; ok {
+		if traversal, diags := hcl.AbsTraversalForExpr(attr.Expr); !diags.HasErrors() {
+			provider = renderTraversal(traversal)
+		}
+	}
+	resource.ProviderConfigKey = getBinaryPlanProviderConfigKey(module.key, provider, parsed)
+
+	return resource
+}
+
+func buildBinaryPlanConfigurationVariable(block *hcl.Block) TerraformPlanConfigurationVariable {
+	var variable TerraformPlanConfigurationVariable
+
+	attributes, _ := block.Body.JustAttributes()
+	if attr, ok := attributes["default"]; ok {
+		variable.Default = buildBinaryPlanExpression(attr.Expr)["constant_value"]
+	}
+	if attr, ok := attributes["description"]; ok {
+		if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && value.IsKnown() {
+			variable.Description = value.AsString()
+		}
+	}
+	if attr, ok := attributes["sensitive"]; ok {
+		if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.Bool && value.IsKnown() {
+			variable.Sensitive = value.True()
+		}
+	}
+	return variable
+}
+
+// getBinaryPlanProviderConfigKey returns the key of the provider configuration
+// used by the resources of a module. Provider configurations are inherited
+// from the parent modules, so the key refers to the closest module declaring
+// the provider, e.g. module.vpc:aws or aws for the root module.
+func getBinaryPlanProviderConfigKey(moduleKey string, provider string, parsed map[string]*binaryPlanConfigModule) string {
+	name, alias, _ := strings.Cut(provider, ".")
+	for key := moduleKey; key != ""; {
+		if module, ok := parsed[key]; ok {
+			for _, block := range module.blocks {
+				if block.Type != "provider" || block.Labels[0] != name {
+					continue
+				}
+				attributes, _ := block.Body.JustAttributes()
+				declaredAlias := ""
+				if attr, ok := attributes["alias"]; ok {
+					if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String && value.IsKnown() {
+						declaredAlias = value.AsString()
+					}
+				}
+				if declaredAlias == alias {
+					return "module." + strings.ReplaceAll(key, ".", ".module.") + ":" + provider
+				}
+			}
+		}
+		i := strings.LastIndex(key, ".")
+		if i == -1 {
+			break
+		}
+		key = key[:i]
+	}
+	return provider
+}
+
+// buildBinaryPlanExpressions returns the expressions of the attributes and
+// nested blocks of a block body, in the format of the JSON plan. Without the
+// provider schemas, nested blocks of .tf.json files cannot be told apart from
+// attributes, so they are returned as attribute expressions.
+func buildBinaryPlanExpressions(body hcl.Body, metaAttributes map[string]bool, metaBlocks map[string]bool) map[string]interface{} {
+	expressions := map[string]interface{}{}
+
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		attributes, _ := body.JustAttributes()
+		for name, attr := range attributes {
+			if metaAttributes[name] || metaBlocks[name] {
+				continue
+			}
+			expressions[name] = buildBinaryPlanExpression(attr.Expr)
+		}
+		return expressions
+	}
+
+	for name, attr := range syntaxBody.Attributes {
+		if metaAttributes[name] {
+			continue
+		}
+		expressions[name] = buildBinaryPlanExpression(attr.Expr)
+	}
+	for _, block := range syntaxBody.Blocks {
+		if metaBlocks[block.Type] {
+			continue
+		}
+		blocks, _ := expressions[block.Type].([]interface{})
+		expressions[block.Type] = append(blocks, buildBinaryPlanExpressions(block.Body, nil, metaBlocks))
+	}
+	return expressions
+}
+
+// buildBinaryPlanExpression returns either the constant value of an
+// expression, or the references it contains
+func buildBinaryPlanExpression(expr hcl.Expression) map[string]interface{} {
+	expression := map[string]interface{}{}
+
+	var references []interface{}
+	seen := map[string]bool{}
+	add := func(reference string) {
+		if !seen[reference] {
+			seen[reference] = true
+			references = append(references, reference)
+		}
+	}
+	for _, traversal := range expr.Variables() {
+		for _, reference := range getBinaryPlanTraversalReferences(traversal) {
+			add(reference)
+		}
+	}
+	if len(references) > 0 {
+		expression["references"] = references
+		return expression
+	}
+
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsWhollyKnown() {
+		return expression
+	}
+	raw, err := ctyjson.Marshal(value, value.Type())
+	if err != nil {
+		return expression
+	}
+	var constantValue interface{}
+	if err := json.Unmarshal(raw, &constantValue); err == nil {
+		expression["constant_value"] = constantValue
+	}
+	return expression
+}
+
+// getBinaryPlanTraversalReferences returns the references of a traversal, as
+// listed in the JSON plan. The full traversal is unwrapped step by step down
+// to the referenced object, and references to resource or module instances
+// also include the resource or module itself.
+func getBinaryPlanTraversalReferences(traversal hcl.Traversal) []string {
+	// The number of steps of the referenced object, e.g. 2 for var.name or
+	// aws_instance.web, or 3 for data.aws_ami.ubuntu
+	subjectSteps := 2
+	switch traversal.RootName() {
+	case "self", "terraform":
+		subjectSteps = 1
+	case "data":
+		subjectSteps = 3
+	}
+	if len(traversal) < subjectSteps {
+		return []string{renderTraversal(traversal)}
+	}
+
+	// Resources and module calls may be followed by an instance key
+	instanceSteps := subjectSteps
+	switch traversal.RootName() {
+	case "var", "local", "count", "each", "path", "self", "terraform":
+	default:
+		if len(traversal) > subjectSteps {
+			if _, ok := traversal[subjectSteps].(hcl.TraverseIndex); ok {
+				instanceSteps++
+			}
+		}
+	}
+	// Outputs of module calls are part of the referenced object
+	if traversal.RootName() == "module" && len(traversal) > instanceSteps {
+		if _, ok := traversal[instanceSteps].(hcl.TraverseAttr); ok {
+			instanceSteps++
+		}
+	}
+
+	var references []string
+	for i := len(traversal); i >= instanceSteps; i-- {
+		references = append(references, renderTraversal(traversal[:i]))
+	}
+	if instanceSteps > subjectSteps {
+		references = append(references, renderTraversal(traversal[:subjectSteps]))
+	}
+	return references
+}
+
+// buildBinaryPlanRepetitionExpressions returns the count or for_each
+// expression of a block, omitting expressions that are neither constant nor
+// contain references
+func buildBinaryPlanRepetitionExpressions(attributes hcl.Attributes) (countExpression interface{}, forEachExpression interface{}) {
+	if attr, ok := attributes["count"]; ok {
+		if expression := buildBinaryPlanExpression(attr.Expr); len(expression) > 0 {
+			return expression, nil
+		}
+	}
+	if attr, ok := attributes["for_each"]; ok {
+		if expression := buildBinaryPlanExpression(attr.Expr); len(expression) > 0 {
+			return nil, expression
+		}
+	}
+	return nil, nil
+}
+
+func buildBinaryPlanDependsOn(attributes hcl.Attributes) []string {
+	attr, ok := attributes["depends_on"]
+	if !ok {
+		return nil
+	}
+	traversals, diags := hcl.ExprList(attr.Expr)
+	if diags.HasErrors() {
+		return nil
+	}
+
+	var dependsOn []string
+	for _, expr := range traversals {
+		traversal, diags := hcl.AbsTraversalForExpr(expr)
+		if diags.HasErrors() {
+			continue
+		}
+		dependsOn = append(dependsOn, renderTraversal(traversal))
+	}
+	return dependsOn
+}
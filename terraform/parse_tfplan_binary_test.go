@@ -0,0 +1,371 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// msgpackUnknown is the msgpack encoding of an unknown value
+var msgpackUnknown = []byte{0xd4, 0x00, 0x00}
+
+// encodeTestMsgpackObject encodes an object with sorted attributes, where nil
+// values are encoded as unknown values
+func encodeTestMsgpackObject(t *testing.T, object map[string]interface{}) []byte {
+	var keys []string
+	for k := range object {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	if err := enc.EncodeMapLen(len(keys)); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range keys {
+		if err := enc.EncodeString(k); err != nil {
+			t.Fatal(err)
+		}
+		if object[k] == nil {
+			buf.Write(msgpackUnknown)
+			continue
+		}
+		if err := enc.Encode(object[k]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func appendTestProtoBytes(b []byte, num protowire.Number, data []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, data)
+}
+
+func appendTestProtoVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// buildTestBinaryPlanChange encodes a Change message with the given msgpack
+// encoded values
+func buildTestBinaryPlanChange(action uint64, values ...[]byte) []byte {
+	var change []byte
+	change = appendTestProtoVarint(change, changeFieldAction, action)
+	for _, value := range values {
+		change = appendTestProtoBytes(change, changeFieldValues, appendTestProtoBytes(nil, dynamicValueFieldMsgpack, value))
+	}
+	return change
+}
+
+func buildTestBinaryPlanResourceChange(address string, action uint64, reason uint64, values ...[]byte) []byte {
+	var resourceChange []byte
+	resourceChange = appendTestProtoBytes(resourceChange, resourceChangeFieldAddr, []byte(address))
+	resourceChange = appendTestProtoBytes(resourceChange, resourceChangeFieldPrevRunAddr, []byte(address))
+	resourceChange = appendTestProtoBytes(resourceChange, resourceChangeFieldProvider, []byte(`provider["registry.terraform.io/hashicorp/aws"]`))
+	resourceChange = appendTestProtoBytes(resourceChange, resourceChangeFieldChange, buildTestBinaryPlanChange(action, values...))
+	if reason != 0 {
+		resourceChange = appendTestProtoVarint(resourceChange, resourceChangeFieldActionReason, reason)
+	}
+	return resourceChange
+}
+
+// testBinaryPlanState is the state stored in the test binary plan
+const testBinaryPlanState = `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "serial": 3,
+  "lineage": "c4d7a3e1-0000-0000-0000-000000000000",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "old",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"schema_version": 1, "attributes": {"ami": "ami-0", "id": "i-0"}}
+      ]
+    },
+    {
+      "module": "module.m[0]",
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "b",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {"index_key": "x", "schema_version": 0, "attributes": {"bucket": "b"}}
+      ]
+    }
+  ]
+}`
+
+// testBinaryPlanJSON is the output of "terraform show -json" for the test
+// binary plan
+const testBinaryPlanJSON = `{
+  "format_version": "1.2",
+  "terraform_version": "1.9.0",
+  "resource_changes": [
+    {
+      "address": "aws_instance.web",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"ami": "ami-1"},
+        "after_unknown": {"id": true},
+        "before_sensitive": false,
+        "after_sensitive": {}
+      }
+    },
+    {
+      "address": "module.m[0].aws_s3_bucket.b[\"x\"]",
+      "module_address": "module.m[0]",
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "b",
+      "index": "x",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {
+        "actions": ["delete", "create"],
+        "before": {"bucket": "b"},
+        "after": {"bucket": "b"},
+        "after_unknown": {"arn": true},
+        "before_sensitive": {},
+        "after_sensitive": {}
+      },
+      "action_reason": "replace_because_tainted"
+    },
+    {
+      "address": "aws_instance.old",
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "old",
+      "provider_name": "registry.terraform.io/hashicorp/aws",
+      "change": {
+        "actions": ["delete"],
+        "before": {"ami": "ami-0", "id": "i-0"},
+        "after": null,
+        "after_unknown": {},
+        "before_sensitive": {},
+        "after_sensitive": false
+      },
+      "action_reason": "delete_because_no_resource_config"
+    }
+  ],
+  "output_changes": {
+    "ip": {
+      "actions": ["create"],
+      "before": null,
+      "after_unknown": true,
+      "before_sensitive": false,
+      "after_sensitive": false
+    }
+  },
+  "prior_state": {
+    "format_version": "1.0",
+    "terraform_version": "1.9.0",
+    "values": {
+      "root_module": {
+        "resources": [
+          {
+            "address": "aws_instance.old",
+            "mode": "managed",
+            "type": "aws_instance",
+            "name": "old",
+            "values": {"ami": "ami-0", "id": "i-0"}
+          }
+        ],
+        "child_modules": [
+          {
+            "address": "module.m[0]",
+            "resources": [
+              {
+                "address": "module.m[0].aws_s3_bucket.b[\"x\"]",
+                "mode": "managed",
+                "type": "aws_s3_bucket",
+                "name": "b",
+                "index": "x",
+                "values": {"bucket": "b"}
+              }
+            ]
+          }
+        ]
+      }
+    }
+  }
+}`
+
+// buildTestBinaryPlan builds a binary plan file with the same content as
+// testBinaryPlanJSON, along with a configuration snapshot
+func buildTestBinaryPlan(t *testing.T) []byte {
+	var plan []byte
+	plan = appendTestProtoBytes(plan, planFieldTerraformVersion, []byte("1.9.0"))
+	plan = appendTestProtoVarint(plan, planFieldApplyable, 1)
+	plan = appendTestProtoVarint(plan, planFieldComplete, 1)
+	plan = appendTestProtoBytes(plan, planFieldResourceChanges, buildTestBinaryPlanResourceChange(
+		"aws_instance.web", 1, 0,
+		encodeTestMsgpackObject(t, map[string]interface{}{"ami": "ami-1", "id": nil}),
+	))
+	plan = appendTestProtoBytes(plan, planFieldResourceChanges, buildTestBinaryPlanResourceChange(
+		`module.m[0].aws_s3_bucket.b["x"]`, 6, 1,
+		encodeTestMsgpackObject(t, map[string]interface{}{"bucket": "b"}),
+		encodeTestMsgpackObject(t, map[string]interface{}{"bucket": "b", "arn": nil}),
+	))
+	plan = appendTestProtoBytes(plan, planFieldResourceChanges, buildTestBinaryPlanResourceChange(
+		"aws_instance.old", 5, 4,
+		encodeTestMsgpackObject(t, map[string]interface{}{"ami": "ami-0", "id": "i-0"}),
+	))
+
+	var outputChange []byte
+	outputChange = appendTestProtoBytes(outputChange, outputChangeFieldName, []byte("ip"))
+	outputChange = appendTestProtoBytes(outputChange, outputChangeFieldChange, buildTestBinaryPlanChange(1, msgpackUnknown))
+	plan = appendTestProtoBytes(plan, planFieldOutputChanges, outputChange)
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{binaryPlanEntryName, string(plan)},
+		{binaryStateEntryName, testBinaryPlanState},
+		{binaryConfigManifestName, `[{"Key": "", "Source": "", "Dir": "."}, {"Key": "m", "Source": "./m", "Dir": "m"}]`},
+		{"tfconfig/m-/main.tf", `
+variable "name" {
+  default = "b"
+}
+
+resource "aws_instance" "web" {
+  ami = "ami-1"
+}
+
+module "m" {
+  source = "./m"
+  count  = 1
+}
+`},
+		{"tfconfig/m-/extra.tf.json", `{"resource": {"aws_s3_bucket": {"json": {"bucket": "${var.name}"}}}}`},
+		{"tfconfig/m-/extra_override.tf.json", `{"resource": {"aws_s3_bucket": {"override": {"bucket": "o"}}}}`},
+		{"tfconfig/m-/nooverride.tf", `resource "aws_s3_bucket" "nooverride" {}`},
+		{"tfconfig/m-m/main.tf", `
+resource "aws_s3_bucket" "b" {
+  for_each = toset(["x"])
+  bucket   = "b"
+}
+`},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(file.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGetTerraformBinaryPlanContent(t *testing.T) {
+	content := buildTestBinaryPlan(t)
+	if !isTerraformPlan(content) {
+		t.Fatal("binary plan not detected as a plan")
+	}
+	got, err := getTerraformPlanContentFromBytes(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := getTerraformPlanContentFromBytes([]byte(testBinaryPlanJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("resource changes", func(t *testing.T) {
+		if len(got.ResourceChanges) != len(want.ResourceChanges) {
+			t.Fatalf("got %d resource changes, want %d", len(got.ResourceChanges), len(want.ResourceChanges))
+		}
+		for i := range want.ResourceChanges {
+			if !reflect.DeepEqual(got.ResourceChanges[i], want.ResourceChanges[i]) {
+				gotJSON, _ := json.Marshal(got.ResourceChanges[i])
+				wantJSON, _ := json.Marshal(want.ResourceChanges[i])
+				t.Errorf("got resource change\n%s\nwant\n%s", gotJSON, wantJSON)
+			}
+		}
+	})
+
+	t.Run("output changes", func(t *testing.T) {
+		if !reflect.DeepEqual(got.OutputChanges, want.OutputChanges) {
+			t.Errorf("got output changes %#v, want %#v", got.OutputChanges, want.OutputChanges)
+		}
+	})
+
+	t.Run("prior state", func(t *testing.T) {
+		if got.PriorState == nil {
+			t.Fatal("got no prior state")
+		}
+		gotResources := getTerraformPlanModuleResources(got.PriorState.Values.RootModule)
+		wantResources := getTerraformPlanModuleResources(want.PriorState.Values.RootModule)
+		if len(gotResources) != len(wantResources) {
+			t.Fatalf("got %d prior state resources, want %d", len(gotResources), len(wantResources))
+		}
+		for i, want := range wantResources {
+			got := gotResources[i]
+			if got.Address != want.Address || got.ModuleAddress != want.ModuleAddress || got.Mode != want.Mode ||
+				got.Type != want.Type || got.Name != want.Name || !reflect.DeepEqual(got.Index, want.Index) || !reflect.DeepEqual(got.Values, want.Values) {
+				t.Errorf("got prior state resource %+v, want %+v", got, want)
+			}
+		}
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		if got.TerraformVersion != "1.9.0" {
+			t.Errorf("got terraform version %q, want 1.9.0", got.TerraformVersion)
+		}
+		if got.Applyable == nil || !*got.Applyable || got.Complete == nil || !*got.Complete {
+			t.Errorf("got applyable %v and complete %v, want true", got.Applyable, got.Complete)
+		}
+		if got.Errored == nil || *got.Errored {
+			t.Errorf("got errored %v, want false", got.Errored)
+		}
+	})
+
+	t.Run("configuration", func(t *testing.T) {
+		var addresses []string
+		for _, resource := range getTerraformPlanConfigurationResources("", got.Configuration.RootModule) {
+			addresses = append(addresses, joinModuleAddress(resource.ModuleAddress, resource.Address))
+		}
+		sort.Strings(addresses)
+		wantAddresses := []string{"aws_instance.web", "aws_s3_bucket.json", "aws_s3_bucket.nooverride", "module.m.aws_s3_bucket.b"}
+		if !reflect.DeepEqual(addresses, wantAddresses) {
+			t.Errorf("got configuration resources %v, want %v", addresses, wantAddresses)
+		}
+
+		for _, resource := range got.Configuration.RootModule.Resources {
+			if resource.Address != "aws_s3_bucket.json" {
+				continue
+			}
+			want := map[string]interface{}{"references": []interface{}{"var.name"}}
+			if !reflect.DeepEqual(resource.Expressions["bucket"], want) {
+				t.Errorf("got bucket expression %v, want %v", resource.Expressions["bucket"], want)
+			}
+		}
+
+		if got.Variables["name"].Value != "b" {
+			t.Errorf("got variable value %v, want the default value b", got.Variables["name"].Value)
+		}
+	})
+}
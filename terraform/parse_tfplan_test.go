@@ -0,0 +1,51 @@
+package terraform
+
+import "testing"
+
+func TestBuildTerraformPlanOutputChangeSensitive(t *testing.T) {
+	tests := []struct {
+		name   string
+		change TerraformPlanChange
+		output TerraformPlanOutput
+		want   bool
+	}{
+		{
+			name:   "deleted sensitive output",
+			change: TerraformPlanChange{Actions: []string{"delete"}, BeforeSensitive: true, AfterSensitive: false},
+			want:   true,
+		},
+		{
+			name:   "created sensitive output",
+			change: TerraformPlanChange{Actions: []string{"create"}, BeforeSensitive: false, AfterSensitive: true},
+			want:   true,
+		},
+		{
+			name:   "output no longer sensitive",
+			change: TerraformPlanChange{Actions: []string{"update"}, BeforeSensitive: true, AfterSensitive: false},
+			want:   false,
+		},
+		{
+			name:   "plan without sensitivity",
+			change: TerraformPlanChange{Actions: []string{"no-op"}},
+			output: TerraformPlanOutput{Sensitive: true},
+			want:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := buildTerraformPlanOutputChange("tfplan.json", "out", test.change, test.output).Sensitive
+			if got != test.want {
+				t.Errorf("got sensitive %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBuildTerraformPlanVariableSensitive(t *testing.T) {
+	// Sensitive values are returned as stored in the plan, like the values of
+	// sensitive outputs
+	got := buildTerraformPlanVariable("tfplan.json", "password", TerraformPlanVariable{Value: "secret"}, TerraformPlanConfigurationVariable{Sensitive: true})
+	if !got.Sensitive || got.Value != "secret" {
+		t.Errorf("got sensitive %v and value %v, want true and secret", got.Sensitive, got.Value)
+	}
+}
@@ -0,0 +1,417 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/Checkmarx/kics/pkg/model"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// TerraformStateCheckObject represents the result of the checks of a single
+// instance of a checkable object recorded in a state file
+type TerraformStateCheckObject struct {
+	ObjectAddr      string   `json:"object_addr"`
+	Status          string   `json:"status"`
+	FailureMessages []string `json:"failure_messages"`
+}
+
+// TerraformStateCheckResult represents the aggregated result of the checks of
+// a checkable object recorded in a state file
+type TerraformStateCheckResult struct {
+	ObjectKind string                      `json:"object_kind"`
+	ConfigAddr string                      `json:"config_addr"`
+	Status     string                      `json:"status"`
+	Objects    []TerraformStateCheckObject `json:"objects"`
+}
+
+// TerraformStateInstance represents an instance of a resource recorded in a
+// state file
+type TerraformStateInstance struct {
+	IndexKey            interface{}            `json:"index_key"`
+	Status              string                 `json:"status"`
+	Deposed             string                 `json:"deposed"`
+	SchemaVersion       int                    `json:"schema_version"`
+	Attributes          map[string]interface{} `json:"attributes"`
+	Dependencies        []string               `json:"dependencies"`
+	CreateBeforeDestroy bool                   `json:"create_before_destroy"`
+	// LegacyDeposedIndex is the position of a deposed object of a legacy
+	// state, which does not record deposed keys
+	LegacyDeposedIndex *int `json:"-"`
+}
+
+// TerraformStateResource represents a resource recorded in a state file
+type TerraformStateResource struct {
+	Module    string                   `json:"module"`
+	Mode      string                   `json:"mode"`
+	Type      string                   `json:"type"`
+	Name      string                   `json:"name"`
+	Provider  string                   `json:"provider"`
+	Instances []TerraformStateInstance `json:"instances"`
+}
+
+// TerraformStateOutput represents a root module output recorded in a state
+// file
+type TerraformStateOutput struct {
+	Value     interface{} `json:"value"`
+	Type      interface{} `json:"type"`
+	Sensitive bool        `json:"sensitive"`
+}
+
+type TerraformStateContentStruct struct {
+	Version          int                             `json:"version"`
+	TerraformVersion string                          `json:"terraform_version"`
+	Serial           int64                           `json:"serial"`
+	Lineage          string                          `json:"lineage"`
+	Outputs          map[string]TerraformStateOutput `json:"outputs"`
+	Resources        []TerraformStateResource        `json:"resources"`
+	CheckResults     []TerraformStateCheckResult     `json:"check_results"`
+	// Modules is only used by the legacy state format (version 3), and is
+	// normalized into the outputs and resources of the current format
+	Modules       []TerraformLegacyStateModule `json:"modules"`
+	IsLegacyState bool                         `json:"-"`
+}
+
+func getTerraformStateContentFromBytes(path string, rawContent []byte) (*TerraformStateContentStruct, error) {
+	var stateContent TerraformStateContentStruct
+	err := json.Unmarshal(rawContent, &stateContent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
+	}
+
+	if isTerraformLegacyState(&stateContent) {
+		if err := normalizeTerraformLegacyState(&stateContent); err != nil {
+			return nil, fmt.Errorf("failed to parse legacy state file %s: %v", path, err)
+		}
+		stateContent.IsLegacyState = true
+	}
+
+	return &stateContent, nil
+}
+
+func buildTerraformState(path string, stateContent *TerraformStateContentStruct) *terraformState {
+	tfState := new(terraformState)
+
+	tfState.Path = path
+	tfState.Version = stateContent.Version
+	tfState.TerraformVersion = stateContent.TerraformVersion
+	tfState.Serial = stateContent.Serial
+	tfState.Lineage = stateContent.Lineage
+	tfState.OutputCount = len(stateContent.Outputs)
+
+	for _, resource := range stateContent.Resources {
+		if resource.Mode == "data" {
+			tfState.DataResourceCount++
+		} else {
+			tfState.ResourceCount++
+		}
+		tfState.InstanceCount += len(resource.Instances)
+	}
+
+	return tfState
+}
+
+// buildTerraformStateResource returns the row of an instance of a resource
+// recorded in a state. It is used for legacy states, whose resources cannot be
+// located in the file.
+func buildTerraformStateResource(path string, resource TerraformStateResource, instance TerraformStateInstance) *terraformResource {
+	tfResource := &terraformResource{
+		Path:                path,
+		Mode:                resource.Mode,
+		Type:                resource.Type,
+		Name:                resource.Name,
+		Provider:            resource.Provider,
+		ModuleAddress:       resource.Module,
+		IndexKey:            instance.IndexKey,
+		Attributes:          instance.Attributes,
+		AttributesStd:       instance.Attributes,
+		Status:              instance.Status,
+		Deposed:             instance.Deposed,
+		IsDeposed:           instance.Deposed != "" || instance.LegacyDeposedIndex != nil,
+		LegacyDeposedIndex:  instance.LegacyDeposedIndex,
+		SchemaVersion:       &instance.SchemaVersion,
+		CreateBeforeDestroy: instance.CreateBeforeDestroy,
+	}
+	for _, dependency := range instance.Dependencies {
+		tfResource.Dependencies = append(tfResource.Dependencies, dependency)
+	}
+	tfResource.Address = buildResourceInstanceAddress(resource.Module, resource.Mode, resource.Type, resource.Name, instance.IndexKey)
+
+	return tfResource
+}
+
+// buildTerraformStateProviders returns a row for each provider configuration
+// referenced by the resources of a state, ordered by address
+func buildTerraformStateProviders(path string, stateContent *TerraformStateContentStruct) []*terraformStateProvider {
+	var providers []*terraformStateProvider
+	providersByAddress := map[string]*terraformStateProvider{}
+
+	for _, resource := range stateContent.Resources {
+		tfProvider, ok := providersByAddress[resource.Provider]
+		if !ok {
+			tfProvider = &terraformStateProvider{Address: resource.Provider, Path: path}
+
+			// Keep the address of unexpected formats, even if it cannot be split
+			moduleAddress, source, alias, err := parseProviderConfigAddress(resource.Provider)
+			if err == nil {
+				tfProvider.ModuleAddress = moduleAddress
+				tfProvider.Source = source
+				tfProvider.Alias = alias
+				tfProvider.Hostname, tfProvider.Namespace, tfProvider.Type = parseProviderSource(source)
+			}
+
+			providersByAddress[resource.Provider] = tfProvider
+			providers = append(providers, tfProvider)
+		}
+		tfProvider.ResourceCount++
+		tfProvider.InstanceCount += len(resource.Instances)
+	}
+
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Address < providers[j].Address
+	})
+	return providers
+}
+
+// The kinds of checkable objects in the state format which differ from the
+// kinds used in the checks section of plans
+var terraformStateCheckKinds = map[string]string{
+	"output": "output_value",
+}
+
+// buildTerraformStateCheckResults returns a row for each instance of a
+// checkable object, or a single row if no instance has been checked yet
+func buildTerraformStateCheckResults(path string, checkResult TerraformStateCheckResult) []*terraformCheckResult {
+	var results []*terraformCheckResult
+
+	kind := checkResult.ObjectKind
+	if planKind, ok := terraformStateCheckKinds[kind]; ok {
+		kind = planKind
+	}
+
+	for _, object := range checkResult.Objects {
+		results = append(results, &terraformCheckResult{
+			Kind:            kind,
+			ConfigAddress:   checkResult.ConfigAddr,
+			ConfigStatus:    checkResult.Status,
+			ObjectAddress:   object.ObjectAddr,
+			Status:          object.Status,
+			FailureMessages: object.FailureMessages,
+			Path:            path,
+		})
+	}
+
+	if len(results) == 0 {
+		results = append(results, &terraformCheckResult{
+			Kind:          kind,
+			ConfigAddress: checkResult.ConfigAddr,
+			ConfigStatus:  checkResult.Status,
+			Status:        checkResult.Status,
+			Path:          path,
+		})
+	}
+
+	return results
+}
+
+// buildTerraformStateOutput returns the row of a root module output recorded
+// in a state. The lines of the output are not located in the states fetched
+// from HTTP backends.
+func buildTerraformStateOutput(ctx context.Context, isHTTPBackendState bool, path string, name string, output TerraformStateOutput) (terraformOutput, error) {
+	tfOutput := terraformOutput{
+		Name:      name,
+		Path:      path,
+		Sensitive: output.Sensitive,
+	}
+
+	if !isHTTPBackendState {
+		startLine, endLine, source, err := findBlockLinesFromJSON(ctx, path, "outputs", name)
+		if err != nil {
+			return tfOutput, err
+		}
+		tfOutput.StartLine = startLine
+		tfOutput.EndLine = endLine
+		tfOutput.Source = source
+	}
+
+	value, err := json.Marshal(output.Value)
+	if err != nil {
+		return tfOutput, fmt.Errorf("failed to convert value of output %s: %v", name, err)
+	}
+	tfOutput.Value = string(value)
+
+	return tfOutput, nil
+}
+
+// buildTerraformStateDiffs matches the resource instances of two states by
+// address, and returns a row for each instance added, removed or changed from
+// the left state to the right state, ordered by address
+func buildTerraformStateDiffs(leftPath string, rightPath string, leftResources []*terraformResource, rightResources []*terraformResource) []*terraformStateDiff {
+	var diffs []*terraformStateDiff
+
+	// Deposed objects share the address of their instance, so they are matched
+	// by their deposed key as well. The deposed objects of legacy states have
+	// no key, so they are only matched by position with the deposed objects of
+	// another legacy state.
+	getKey := func(tfResource *terraformResource) string {
+		if tfResource.LegacyDeposedIndex != nil {
+			return fmt.Sprintf("%s/legacy-deposed/%d", tfResource.Address, *tfResource.LegacyDeposedIndex)
+		}
+		return tfResource.Address + "/" + tfResource.Deposed
+	}
+
+	rightResourcesByKey := map[string]*terraformResource{}
+	for _, rightResource := range rightResources {
+		rightResourcesByKey[getKey(rightResource)] = rightResource
+	}
+
+	leftKeys := map[string]bool{}
+	for _, leftResource := range leftResources {
+		leftKeys[getKey(leftResource)] = true
+
+		rightResource, ok := rightResourcesByKey[getKey(leftResource)]
+		if !ok {
+			diffs = append(diffs, buildTerraformStateDiff(leftPath, rightPath, "removed", leftResource, leftResource, nil))
+			continue
+		}
+
+		diff := buildTerraformStateDiff(leftPath, rightPath, "changed", rightResource, leftResource, rightResource)
+		if len(diff.AttributeDiff) > 0 {
+			diffs = append(diffs, diff)
+		}
+	}
+
+	for _, rightResource := range rightResources {
+		if !leftKeys[getKey(rightResource)] {
+			diffs = append(diffs, buildTerraformStateDiff(leftPath, rightPath, "added", rightResource, nil, rightResource))
+		}
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].Address != diffs[j].Address {
+			return diffs[i].Address < diffs[j].Address
+		}
+		if diffs[i].IsDeposed != diffs[j].IsDeposed {
+			return !diffs[i].IsDeposed
+		}
+		return diffs[i].Deposed < diffs[j].Deposed
+	})
+	return diffs
+}
+
+// buildTerraformStateDiff returns the row of a resource instance, comparing
+// its attributes in the left and right states. Nested objects and lists of the
+// same length are compared element by element, so that each attribute diff is
+// qualified by the path of the value that differs, e.g. tags.Name. Attributes
+// of a legacy state are compared with the attributes of a current state as
+// strings. The left or right resource is nil if the instance was added or
+// removed respectively.
+func buildTerraformStateDiff(leftPath string, rightPath string, change string, tfResource *terraformResource, leftResource *terraformResource, rightResource *terraformResource) *terraformStateDiff {
+	diff := &terraformStateDiff{
+		Address:       tfResource.Address,
+		Change:        change,
+		Mode:          tfResource.Mode,
+		Type:          tfResource.Type,
+		Name:          tfResource.Name,
+		ModuleAddress: tfResource.ModuleAddress,
+		IndexKey:      tfResource.IndexKey,
+		Deposed:       tfResource.Deposed,
+		IsDeposed:     tfResource.IsDeposed,
+		LeftPath:      leftPath,
+		RightPath:     rightPath,
+	}
+
+	var leftAttributes, rightAttributes map[string]interface{}
+	if leftResource != nil {
+		diff.LeftAttributes = leftResource.Attributes
+		leftAttributes = convertModelDocumentToMapInterface(leftResource.Attributes)
+	}
+	if rightResource != nil {
+		diff.RightAttributes = rightResource.Attributes
+		rightAttributes = convertModelDocumentToMapInterface(rightResource.Attributes)
+	}
+
+	// The values of legacy states are all strings, so the values of both states
+	// are compared in the legacy form if either state is a legacy state
+	isLegacyStateDiff := leftResource != nil && rightResource != nil && leftResource.IsLegacyState != rightResource.IsLegacyState
+
+	for _, name := range getTerraformStateAttributeNames(leftAttributes, rightAttributes) {
+		diff.AttributeDiff = appendTerraformStateAttributeDiffs(diff.AttributeDiff, name, leftAttributes[name], rightAttributes[name], isLegacyStateDiff)
+	}
+
+	return diff
+}
+
+// appendTerraformStateAttributeDiffs compares the left and right values of an
+// attribute, descending into the objects and the lists of the same length on
+// both sides, and appends a diff for each value which differs
+func appendTerraformStateAttributeDiffs(diffs []terraformStateAttributeDiff, path string, left interface{}, right interface{}, isLegacyStateDiff bool) []terraformStateAttributeDiff {
+	if isLegacyStateDiff {
+		if reflect.DeepEqual(getTerraformLegacyStateComparableValue(left), getTerraformLegacyStateComparableValue(right)) {
+			return diffs
+		}
+	} else if reflect.DeepEqual(left, right) {
+		return diffs
+	}
+
+	leftObject, leftIsObject := getTerraformStateObjectValue(left)
+	rightObject, rightIsObject := getTerraformStateObjectValue(right)
+	if leftIsObject && rightIsObject {
+		for _, name := range getTerraformStateAttributeNames(leftObject, rightObject) {
+			diffs = appendTerraformStateAttributeDiffs(diffs, path+formatTerraformStateAttributeStep(name), leftObject[name], rightObject[name], isLegacyStateDiff)
+		}
+		return diffs
+	}
+
+	leftList, leftIsList := left.([]interface{})
+	rightList, rightIsList := right.([]interface{})
+	if leftIsList && rightIsList && len(leftList) == len(rightList) {
+		for i := range leftList {
+			diffs = appendTerraformStateAttributeDiffs(diffs, fmt.Sprintf("%s[%d]", path, i), leftList[i], rightList[i], isLegacyStateDiff)
+		}
+		return diffs
+	}
+
+	return append(diffs, terraformStateAttributeDiff{
+		Attribute: path,
+		Left:      left,
+		Right:     right,
+	})
+}
+
+func getTerraformStateObjectValue(value interface{}) (map[string]interface{}, bool) {
+	switch value.(type) {
+	case map[string]interface{}, model.Document:
+		return convertModelDocumentToMapInterface(value), true
+	}
+	return nil, false
+}
+
+// getTerraformStateAttributeNames returns the sorted names of the attributes
+// of either object
+func getTerraformStateAttributeNames(left map[string]interface{}, right map[string]interface{}) []string {
+	var names []string
+	for name := range left {
+		names = append(names, name)
+	}
+	for name := range right {
+		if _, ok := left[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatTerraformStateAttributeStep returns the step of an attribute path
+// for an attribute or map key, e.g. .Name, or ["kubernetes.io/role"] for keys
+// which are not valid identifiers
+func formatTerraformStateAttributeStep(name string) string {
+	if hclsyntax.ValidIdentifier(name) {
+		return "." + name
+	}
+	return "[" + quoteHCLString(name) + "]"
+}
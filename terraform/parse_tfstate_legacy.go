@@ -0,0 +1,335 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Checkmarx/kics/pkg/model"
+)
+
+// The legacy state format (version 3), written by Terraform 0.11 and earlier,
+// groups the resources by module. Each module records its resources in a map
+// keyed by the resource address relative to the module, with one entry per
+// instance, e.g. aws_instance.web.0, and the attributes of each instance as a
+// flat map of strings, e.g. tags.Name.
+
+// TerraformLegacyStateInstance represents the primary or a deposed object of
+// a resource instance in the legacy state format
+type TerraformLegacyStateInstance struct {
+	ID         string                 `json:"id"`
+	Attributes map[string]string      `json:"attributes"`
+	Meta       map[string]interface{} `json:"meta"`
+	Tainted    bool                   `json:"tainted"`
+}
+
+// TerraformLegacyStateResource represents a resource instance in the legacy
+// state format
+type TerraformLegacyStateResource struct {
+	Type      string                         `json:"type"`
+	DependsOn []string                       `json:"depends_on"`
+	Primary   *TerraformLegacyStateInstance  `json:"primary"`
+	Deposed   []TerraformLegacyStateInstance `json:"deposed"`
+	Provider  string                         `json:"provider"`
+}
+
+// TerraformLegacyStateModule represents a module in the legacy state format
+type TerraformLegacyStateModule struct {
+	Path      []string                                `json:"path"`
+	Outputs   map[string]TerraformStateOutput         `json:"outputs"`
+	Resources map[string]TerraformLegacyStateResource `json:"resources"`
+}
+
+func isTerraformLegacyState(stateContent *TerraformStateContentStruct) bool {
+	return stateContent.Version < 4 && len(stateContent.Modules) > 0
+}
+
+// normalizeTerraformLegacyState converts the modules of a legacy state into
+// the outputs and resources of the current state format (version 4)
+func normalizeTerraformLegacyState(stateContent *TerraformStateContentStruct) error {
+	resourcesByAddress := map[string]*TerraformStateResource{}
+	var addresses []string
+
+	for _, module := range stateContent.Modules {
+		moduleAddress := getTerraformLegacyStateModuleAddress(module.Path)
+
+		// Only the outputs of the root module are recorded in the current format
+		if moduleAddress == "" {
+			stateContent.Outputs = module.Outputs
+		}
+
+		for key, legacyResource := range module.Resources {
+			mode, resourceType, name, indexKey, err := parseTerraformLegacyStateResourceKey(key)
+			if err != nil {
+				return fmt.Errorf("failed to normalize module %s: %v", strings.Join(module.Path, "."), err)
+			}
+
+			address := buildResourceInstanceAddress(moduleAddress, mode, resourceType, name, nil)
+			resource, ok := resourcesByAddress[address]
+			if !ok {
+				resource = &TerraformStateResource{
+					Module:   moduleAddress,
+					Mode:     mode,
+					Type:     resourceType,
+					Name:     name,
+					Provider: getTerraformLegacyStateProvider(moduleAddress, resourceType, legacyResource.Provider),
+				}
+				resourcesByAddress[address] = resource
+				addresses = append(addresses, address)
+			}
+
+			// Dependencies are recorded relative to the module, and may refer to
+			// all the instances of a resource, e.g. aws_subnet.a.*
+			var dependencies []string
+			for _, dependency := range legacyResource.DependsOn {
+				dependency = strings.TrimSuffix(dependency, ".*")
+				if moduleAddress != "" {
+					dependency = moduleAddress + "." + dependency
+				}
+				dependencies = append(dependencies, dependency)
+			}
+
+			if legacyResource.Primary != nil {
+				instance := buildTerraformLegacyStateInstance(*legacyResource.Primary, indexKey, dependencies)
+				resource.Instances = append(resource.Instances, instance)
+			}
+
+			// Deposed objects have no key in the legacy format, so they are
+			// marked by their position instead
+			for i, deposed := range legacyResource.Deposed {
+				instance := buildTerraformLegacyStateInstance(deposed, indexKey, dependencies)
+				legacyDeposedIndex := i
+				instance.LegacyDeposedIndex = &legacyDeposedIndex
+				resource.Instances = append(resource.Instances, instance)
+			}
+		}
+	}
+
+	// Map iteration order is random, so sort the resources and their instances
+	sort.Strings(addresses)
+	stateContent.Resources = nil
+	for _, address := range addresses {
+		resource := resourcesByAddress[address]
+		sort.SliceStable(resource.Instances, func(i, j int) bool {
+			a, b := resource.Instances[i], resource.Instances[j]
+			if fmt.Sprint(a.IndexKey) != fmt.Sprint(b.IndexKey) {
+				ai, _ := a.IndexKey.(float64)
+				bi, _ := b.IndexKey.(float64)
+				return ai < bi
+			}
+			return getTerraformLegacyStateDeposedOrder(a) < getTerraformLegacyStateDeposedOrder(b)
+		})
+		stateContent.Resources = append(stateContent.Resources, *resource)
+	}
+	stateContent.Modules = nil
+
+	return nil
+}
+
+// getTerraformLegacyStateDeposedOrder orders the primary object of an
+// instance before its deposed objects
+func getTerraformLegacyStateDeposedOrder(instance TerraformStateInstance) int {
+	if instance.LegacyDeposedIndex == nil {
+		return -1
+	}
+	return *instance.LegacyDeposedIndex
+}
+
+func buildTerraformLegacyStateInstance(legacyInstance TerraformLegacyStateInstance, indexKey interface{}, dependencies []string) TerraformStateInstance {
+	instance := TerraformStateInstance{
+		IndexKey:     indexKey,
+		Attributes:   expandTerraformLegacyStateAttributes(legacyInstance.Attributes),
+		Dependencies: dependencies,
+	}
+	if legacyInstance.Tainted {
+		instance.Status = "tainted"
+	}
+
+	// The schema version is recorded as a string in the meta map
+	if schemaVersion, ok := legacyInstance.Meta["schema_version"]; ok {
+		if version, err := strconv.Atoi(fmt.Sprint(schemaVersion)); err == nil {
+			instance.SchemaVersion = version
+		}
+	}
+
+	return instance
+}
+
+// getTerraformLegacyStateModuleAddress returns the address of a module from
+// its path in the legacy format, e.g. module.vpc.module.subnets for
+// ["root", "vpc", "subnets"]
+func getTerraformLegacyStateModuleAddress(path []string) string {
+	var modulePath []string
+	for i, name := range path {
+		if i == 0 && name == "root" {
+			continue
+		}
+		modulePath = append(modulePath, "module."+name)
+	}
+	return strings.Join(modulePath, ".")
+}
+
+// parseTerraformLegacyStateResourceKey splits the key of a resource instance
+// in the legacy format into its mode, type, name and count index, e.g.
+// data.aws_ami.ubuntu or aws_instance.web.0
+func parseTerraformLegacyStateResourceKey(key string) (mode string, resourceType string, name string, indexKey interface{}, err error) {
+	parts := strings.Split(key, ".")
+
+	mode = "managed"
+	if len(parts) > 0 && parts[0] == "data" {
+		mode = "data"
+		parts = parts[1:]
+	}
+
+	switch len(parts) {
+	case 2:
+	case 3:
+		index, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return "", "", "", nil, fmt.Errorf("invalid resource key %s", key)
+		}
+		indexKey = float64(index)
+	default:
+		return "", "", "", nil, fmt.Errorf("invalid resource key %s", key)
+	}
+
+	return mode, parts[0], parts[1], indexKey, nil
+}
+
+// getTerraformLegacyStateProvider returns the address of the provider
+// configuration of a resource. Resources using the default configuration of
+// their provider have no provider recorded in the legacy format.
+func getTerraformLegacyStateProvider(moduleAddress string, resourceType string, provider string) string {
+	if provider == "" {
+		provider = "provider." + strings.SplitN(resourceType, "_", 2)[0]
+	}
+	if moduleAddress != "" && !strings.HasPrefix(provider, "module.") {
+		provider = moduleAddress + "." + provider
+	}
+	return provider
+}
+
+// expandTerraformLegacyStateAttributes converts the flat map of attributes of
+// the legacy format into nested values. Lists and sets are flattened into a
+// count, e.g. subnets.# = 2, and one key per element, e.g. subnets.0, maps
+// into a count, e.g. tags.% = 1, and one key per element, e.g. tags.Name, and
+// nested blocks into one key per attribute, e.g. root_block_device.0.iops. The
+// values are all kept as strings, since the legacy format does not record the
+// types of the attributes.
+func expandTerraformLegacyStateAttributes(attributes map[string]string) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+	return expandTerraformLegacyStateObject(attributes, "")
+}
+
+func expandTerraformLegacyStateValue(attributes map[string]string, key string) interface{} {
+	if value, ok := attributes[key]; ok {
+		return value
+	}
+	if _, ok := attributes[key+".#"]; ok {
+		return expandTerraformLegacyStateList(attributes, key+".")
+	}
+	if _, ok := attributes[key+".%"]; ok {
+		return expandTerraformLegacyStateMap(attributes, key+".")
+	}
+	return expandTerraformLegacyStateObject(attributes, key+".")
+}
+
+func expandTerraformLegacyStateList(attributes map[string]string, prefix string) []interface{} {
+	// The elements of sets are keyed by a hash rather than by their index, so
+	// collect the keys of the elements rather than relying on the count
+	var elementKeys []string
+	seen := map[string]bool{}
+	for key := range attributes {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		elementKey := strings.SplitN(strings.TrimPrefix(key, prefix), ".", 2)[0]
+		if elementKey == "#" || seen[elementKey] {
+			continue
+		}
+		seen[elementKey] = true
+		elementKeys = append(elementKeys, elementKey)
+	}
+
+	sort.Slice(elementKeys, func(i, j int) bool {
+		a, errA := strconv.Atoi(elementKeys[i])
+		b, errB := strconv.Atoi(elementKeys[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return elementKeys[i] < elementKeys[j]
+	})
+
+	list := []interface{}{}
+	for _, elementKey := range elementKeys {
+		list = append(list, expandTerraformLegacyStateValue(attributes, prefix+elementKey))
+	}
+	return list
+}
+
+// expandTerraformLegacyStateMap returns the elements of a map attribute. The
+// keys of the elements may contain dots, e.g. kubernetes.io/role, so they are
+// not split any further.
+func expandTerraformLegacyStateMap(attributes map[string]string, prefix string) map[string]interface{} {
+	expanded := map[string]interface{}{}
+	for key, value := range attributes {
+		if !strings.HasPrefix(key, prefix) || key == prefix+"%" {
+			continue
+		}
+		expanded[strings.TrimPrefix(key, prefix)] = value
+	}
+	return expanded
+}
+
+func expandTerraformLegacyStateObject(attributes map[string]string, prefix string) map[string]interface{} {
+	expanded := map[string]interface{}{}
+	for key := range attributes {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		name := strings.SplitN(strings.TrimPrefix(key, prefix), ".", 2)[0]
+		if _, ok := expanded[name]; !ok {
+			expanded[name] = expandTerraformLegacyStateValue(attributes, prefix+name)
+		}
+	}
+	return expanded
+}
+
+// getTerraformLegacyStateComparableValue converts an attribute value into the
+// form of the expanded attributes of the legacy format, i.e., with primitive
+// values as strings, and without null values or empty collections, which are
+// not recorded in the legacy format
+func getTerraformLegacyStateComparableValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case bool:
+		return strconv.FormatBool(value)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case []interface{}:
+		if len(value) == 0 {
+			return nil
+		}
+		list := make([]interface{}, len(value))
+		for i, element := range value {
+			list[i] = getTerraformLegacyStateComparableValue(element)
+		}
+		return list
+	case map[string]interface{}, model.Document:
+		object := map[string]interface{}{}
+		for key, element := range convertModelDocumentToMapInterface(value) {
+			if comparableElement := getTerraformLegacyStateComparableValue(element); comparableElement != nil {
+				object[key] = comparableElement
+			}
+		}
+		if len(object) == 0 {
+			return nil
+		}
+		return object
+	}
+	return fmt.Sprint(value)
+}
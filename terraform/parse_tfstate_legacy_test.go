@@ -0,0 +1,196 @@
+package terraform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLegacyState = `{
+  "version": 3,
+  "terraform_version": "0.11.14",
+  "serial": 7,
+  "lineage": "legacy",
+  "modules": [
+    {
+      "path": ["root"],
+      "outputs": {
+        "ip": {"sensitive": false, "type": "string", "value": "10.0.0.1"}
+      },
+      "resources": {
+        "aws_instance.web.1": {
+          "type": "aws_instance",
+          "primary": {"id": "i-1", "attributes": {"id": "i-1"}, "meta": {"schema_version": "1"}},
+          "provider": "provider.aws"
+        },
+        "aws_instance.web.0": {
+          "type": "aws_instance",
+          "depends_on": ["aws_subnet.a.*"],
+          "primary": {"id": "i-0", "attributes": {"id": "i-0"}, "tainted": true},
+          "deposed": [
+            {"id": "i-old0", "attributes": {"id": "i-old0"}},
+            {"id": "i-old1", "attributes": {"id": "i-old1"}}
+          ],
+          "provider": "provider.aws"
+        },
+        "data.aws_ami.ubuntu": {
+          "type": "aws_ami",
+          "primary": {"id": "ami-1", "attributes": {"id": "ami-1"}},
+          "provider": "provider.aws"
+        }
+      }
+    },
+    {
+      "path": ["root", "vpc", "subnets"],
+      "outputs": {},
+      "resources": {
+        "aws_subnet.a": {
+          "type": "aws_subnet",
+          "depends_on": ["aws_vpc.main"],
+          "primary": {
+            "id": "subnet-1",
+            "attributes": {
+              "id": "subnet-1",
+              "tags.%": "2",
+              "tags.Name": "a",
+              "tags.kubernetes.io/role": "elb",
+              "cidr_blocks.#": "2",
+              "cidr_blocks.0": "10.0.0.0/24",
+              "cidr_blocks.1": "10.0.1.0/24",
+              "security_groups.#": "1",
+              "security_groups.3241353547": "sg-1",
+              "ingress.#": "1",
+              "ingress.2214680975.from_port": "80",
+              "ingress.2214680975.cidr_blocks.#": "1",
+              "ingress.2214680975.cidr_blocks.0": "0.0.0.0/0",
+              "root_block_device.#": "1",
+              "root_block_device.0.iops": "100"
+            }
+          }
+        }
+      }
+    }
+  ]
+}`
+
+func TestNormalizeTerraformLegacyState(t *testing.T) {
+	stateContent, err := getTerraformStateContentFromBytes("terraform.tfstate", []byte(testLegacyState))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stateContent.IsLegacyState {
+		t.Fatal("state not detected as a legacy state")
+	}
+	if stateContent.Outputs["ip"].Value != "10.0.0.1" {
+		t.Errorf("got outputs %v, want the outputs of the root module", stateContent.Outputs)
+	}
+
+	type instance struct {
+		address            string
+		provider           string
+		status             string
+		schemaVersion      int
+		dependencies       []string
+		legacyDeposedIndex *int
+	}
+	zero, one := 0, 1
+	want := []instance{
+		{address: "aws_instance.web[0]", provider: "provider.aws", status: "tainted", dependencies: []string{"aws_subnet.a"}},
+		{address: "aws_instance.web[0]", provider: "provider.aws", dependencies: []string{"aws_subnet.a"}, legacyDeposedIndex: &zero},
+		{address: "aws_instance.web[0]", provider: "provider.aws", dependencies: []string{"aws_subnet.a"}, legacyDeposedIndex: &one},
+		{address: "aws_instance.web[1]", provider: "provider.aws", schemaVersion: 1},
+		{address: "data.aws_ami.ubuntu", provider: "provider.aws"},
+		{address: "module.vpc.module.subnets.aws_subnet.a", provider: "module.vpc.module.subnets.provider.aws", dependencies: []string{"module.vpc.module.subnets.aws_vpc.main"}},
+	}
+
+	var got []instance
+	for _, resource := range stateContent.Resources {
+		for _, i := range resource.Instances {
+			got = append(got, instance{
+				address:            buildResourceInstanceAddress(resource.Module, resource.Mode, resource.Type, resource.Name, i.IndexKey),
+				provider:           resource.Provider,
+				status:             i.Status,
+				schemaVersion:      i.SchemaVersion,
+				dependencies:       i.Dependencies,
+				legacyDeposedIndex: i.LegacyDeposedIndex,
+			})
+			if i.Deposed != "" {
+				t.Errorf("got deposed key %q for %s, legacy states have no deposed keys", i.Deposed, resource.Name)
+			}
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got instances\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Sets and maps are unflattened, and the values are kept as strings
+	wantAttributes := map[string]interface{}{
+		"id":              "subnet-1",
+		"tags":            map[string]interface{}{"Name": "a", "kubernetes.io/role": "elb"},
+		"cidr_blocks":     []interface{}{"10.0.0.0/24", "10.0.1.0/24"},
+		"security_groups": []interface{}{"sg-1"},
+		"ingress": []interface{}{
+			map[string]interface{}{"from_port": "80", "cidr_blocks": []interface{}{"0.0.0.0/0"}},
+		},
+		"root_block_device": []interface{}{
+			map[string]interface{}{"iops": "100"},
+		},
+	}
+	subnet := stateContent.Resources[len(stateContent.Resources)-1]
+	if gotAttributes := subnet.Instances[0].Attributes; !reflect.DeepEqual(gotAttributes, wantAttributes) {
+		t.Errorf("got attributes\n%#v\nwant\n%#v", gotAttributes, wantAttributes)
+	}
+}
+
+func TestNormalizeTerraformLegacyStateInvalidKey(t *testing.T) {
+	state := `{
+  "version": 3,
+  "modules": [
+    {
+      "path": ["root"],
+      "resources": {
+        "aws_instance.web.a": {"type": "aws_instance", "primary": {"id": "i-1"}}
+      }
+    }
+  ]
+}`
+	_, err := getTerraformStateContentFromBytes("terraform.tfstate", []byte(state))
+	if err == nil || !strings.Contains(err.Error(), "aws_instance.web.a") {
+		t.Errorf("got error %v, want an error for the resource key aws_instance.web.a", err)
+	}
+}
+
+func TestParseTerraformLegacyStateResourceKey(t *testing.T) {
+	tests := []struct {
+		key          string
+		mode         string
+		resourceType string
+		name         string
+		indexKey     interface{}
+		wantErr      bool
+	}{
+		{key: "aws_instance.web", mode: "managed", resourceType: "aws_instance", name: "web"},
+		{key: "aws_instance.web.2", mode: "managed", resourceType: "aws_instance", name: "web", indexKey: float64(2)},
+		{key: "data.aws_ami.ubuntu", mode: "data", resourceType: "aws_ami", name: "ubuntu"},
+		{key: "data.aws_ami.ubuntu.0", mode: "data", resourceType: "aws_ami", name: "ubuntu", indexKey: float64(0)},
+		{key: "aws_instance.web.a", wantErr: true},
+		{key: "aws_instance", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			mode, resourceType, name, indexKey, err := parseTerraformLegacyStateResourceKey(test.key)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mode != test.mode || resourceType != test.resourceType || name != test.name || !reflect.DeepEqual(indexKey, test.indexKey) {
+				t.Errorf("got %q, %q, %q, %#v, want %q, %q, %q, %#v", mode, resourceType, name, indexKey, test.mode, test.resourceType, test.name, test.indexKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildTerraformStateDiffs(t *testing.T) {
+	legacyDeposedIndex := 0
+	left := []*terraformResource{
+		{
+			Address: "aws_instance.a",
+			Attributes: map[string]interface{}{
+				"ami":             "ami-1",
+				"tags":            map[string]interface{}{"Name": "a", "Env": "dev"},
+				"ebs":             []interface{}{map[string]interface{}{"size": float64(8)}},
+				"security_groups": []interface{}{"sg-1"},
+			},
+		},
+		{Address: "aws_instance.b", Deposed: "d1", IsDeposed: true, Attributes: map[string]interface{}{"id": "i-old"}},
+		{Address: "aws_instance.c", IsLegacyState: true, Attributes: map[string]interface{}{"id": "i-c", "count": "8"}},
+		{Address: "aws_instance.c", IsLegacyState: true, IsDeposed: true, LegacyDeposedIndex: &legacyDeposedIndex, Attributes: map[string]interface{}{"id": "i-c-old"}},
+		{Address: "aws_instance.removed", Attributes: map[string]interface{}{"id": "i-removed"}},
+		{Address: "aws_instance.same", Attributes: map[string]interface{}{"id": "i-same"}},
+	}
+	right := []*terraformResource{
+		{
+			Address: "aws_instance.a",
+			Attributes: map[string]interface{}{
+				"ami":             "ami-1",
+				"tags":            map[string]interface{}{"Name": "b", "Env": "dev", "kubernetes.io/role": "elb"},
+				"ebs":             []interface{}{map[string]interface{}{"size": float64(10)}},
+				"security_groups": []interface{}{"sg-1", "sg-2"},
+			},
+		},
+		{Address: "aws_instance.added", Attributes: map[string]interface{}{"id": "i-added"}},
+		{Address: "aws_instance.b", Deposed: "d1", IsDeposed: true, Attributes: map[string]interface{}{"id": "i-older"}},
+		{Address: "aws_instance.b", Deposed: "d2", IsDeposed: true, Attributes: map[string]interface{}{"id": "i-new"}},
+		{Address: "aws_instance.c", Attributes: map[string]interface{}{"id": "i-c", "count": float64(8), "tags": map[string]interface{}{}}},
+		{Address: "aws_instance.c", Deposed: "00000000", IsDeposed: true, Attributes: map[string]interface{}{"id": "i-c-old"}},
+		{Address: "aws_instance.same", Attributes: map[string]interface{}{"id": "i-same"}},
+	}
+
+	type diff struct {
+		address       string
+		change        string
+		deposed       string
+		attributeDiff []terraformStateAttributeDiff
+	}
+	want := []diff{
+		{
+			address: "aws_instance.a",
+			change:  "changed",
+			attributeDiff: []terraformStateAttributeDiff{
+				{Attribute: "ebs[0].size", Left: float64(8), Right: float64(10)},
+				{Attribute: "security_groups", Left: []interface{}{"sg-1"}, Right: []interface{}{"sg-1", "sg-2"}},
+				{Attribute: "tags.Name", Left: "a", Right: "b"},
+				{Attribute: `tags["kubernetes.io/role"]`, Left: nil, Right: "elb"},
+			},
+		},
+		{
+			address:       "aws_instance.added",
+			change:        "added",
+			attributeDiff: []terraformStateAttributeDiff{{Attribute: "id", Left: nil, Right: "i-added"}},
+		},
+		{
+			address:       "aws_instance.b",
+			change:        "changed",
+			deposed:       "d1",
+			attributeDiff: []terraformStateAttributeDiff{{Attribute: "id", Left: "i-old", Right: "i-older"}},
+		},
+		{
+			address:       "aws_instance.b",
+			change:        "added",
+			deposed:       "d2",
+			attributeDiff: []terraformStateAttributeDiff{{Attribute: "id", Left: nil, Right: "i-new"}},
+		},
+		// The deposed objects of legacy states have no key, so they are not
+		// matched with the deposed objects of a current state
+		{
+			address:       "aws_instance.c",
+			change:        "removed",
+			attributeDiff: []terraformStateAttributeDiff{{Attribute: "id", Left: "i-c-old", Right: nil}},
+		},
+		{
+			address:       "aws_instance.c",
+			change:        "added",
+			deposed:       "00000000",
+			attributeDiff: []terraformStateAttributeDiff{{Attribute: "id", Left: nil, Right: "i-c-old"}},
+		},
+		{
+			address:       "aws_instance.removed",
+			change:        "removed",
+			attributeDiff: []terraformStateAttributeDiff{{Attribute: "id", Left: "i-removed", Right: nil}},
+		},
+	}
+
+	var got []diff
+	for _, d := range buildTerraformStateDiffs("left.tfstate", "right.tfstate", left, right) {
+		got = append(got, diff{address: d.Address, change: d.Change, deposed: d.Deposed, attributeDiff: d.AttributeDiff})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got diffs\n%+v\nwant\n%+v", got, want)
+	}
+}
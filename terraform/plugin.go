@@ -24,13 +24,30 @@ func Plugin(ctx context.Context) *plugin.Plugin {
 			NewInstance: ConfigInstance,
 		},
 		TableMap: map[string]*plugin.Table{
-			"terraform_data_source": tableTerraformDataSource(ctx),
-			"terraform_local":       tableTerraformLocal(ctx),
-			"terraform_module":      tableTerraformModule(ctx),
-			"terraform_output":      tableTerraformOutput(ctx),
-			"terraform_provider":    tableTerraformProvider(ctx),
-			"terraform_resource":    tableTerraformResource(ctx),
-			"terraform_variable":    tableTerraformVariable(ctx),
+			"terraform_check_result":                tableTerraformCheckResult(ctx),
+			"terraform_data_source":                 tableTerraformDataSource(ctx),
+			"terraform_import":                      tableTerraformImport(ctx),
+			"terraform_local":                       tableTerraformLocal(ctx),
+			"terraform_lock_provider":               tableTerraformLockProvider(ctx),
+			"terraform_module":                      tableTerraformModule(ctx),
+			"terraform_output":                      tableTerraformOutput(ctx),
+			"terraform_plan":                        tableTerraformPlan(ctx),
+			"terraform_plan_configuration_resource": tableTerraformPlanConfigurationResource(ctx),
+			"terraform_plan_module_call":            tableTerraformPlanModuleCall(ctx),
+			"terraform_plan_output_change":          tableTerraformPlanOutputChange(ctx),
+			"terraform_plan_variable":               tableTerraformPlanVariable(ctx),
+			"terraform_provider":                    tableTerraformProvider(ctx),
+			"terraform_required_provider":           tableTerraformRequiredProvider(ctx),
+			"terraform_resource":                    tableTerraformResource(ctx),
+			"terraform_resource_change":             tableTerraformResourceChange(ctx),
+			"terraform_resource_drift":              tableTerraformResourceDrift(ctx),
+			"terraform_resource_reconciliation":     tableTerraformResourceReconciliation(ctx),
+			"terraform_settings":                    tableTerraformSettings(ctx),
+			"terraform_state":                       tableTerraformState(ctx),
+			"terraform_state_diff":                  tableTerraformStateDiff(ctx),
+			"terraform_state_provider":              tableTerraformStateProvider(ctx),
+			"terraform_variable":                    tableTerraformVariable(ctx),
+			"terraform_version_constraint_check":    tableTerraformVersionConstraintCheck(ctx),
 		},
 	}
 
@@ -0,0 +1,115 @@
+package terraform
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func tableTerraformCheckResult(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_check_result",
+		Description: "Terraform check and condition results from plan and state files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfStateList,
+			Hydrate:       listCheckResults,
+			KeyColumns:    plugin.OptionalColumns([]string{"path", "workspace"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "object_address",
+				Description: "The absolute address of the checked object instance, e.g. aws_s3_bucket.b[0].",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "config_address",
+				Description: "The address of the checkable object in the configuration, e.g. aws_s3_bucket.b.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "kind",
+				Description: "The kind of the checkable object, one of resource, output_value, check or var.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "status",
+				Description: "The status of the checks of the object instance, one of pass, fail, error or unknown.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "config_status",
+				Description: "The aggregated status of the checks of all the instances of the checkable object, one of pass, fail, error or unknown.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "failure_messages",
+				Description: "The error messages of the failed checks of the object instance.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "workspace",
+				Description: "The Terraform CLI workspace of the state file, e.g. default, if the file follows the layout of the local backend, i.e., terraform.tfstate or terraform.tfstate.d/<workspace>/terraform.tfstate.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformCheckResult struct {
+	ObjectAddress   string
+	ConfigAddress   string
+	Kind            string
+	Status          string
+	ConfigStatus    string
+	FailureMessages []string
+	Workspace       string
+	Path            string
+}
+
+func listCheckResults(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	content, err := readStateFileContent(ctx, d, pathInfo)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_check_result.listCheckResults", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// Check results are recorded in the checks section of plans, and in the
+	// check_results of states
+	if isTerraformPlan(content) {
+		planContent, err := getTerraformPlanContentFromBytes(content)
+		if err != nil {
+			plugin.Logger(ctx).Error("terraform_check_result.listCheckResults", "get_plan_content_error", err, "path", path)
+			return nil, err
+		}
+		for _, check := range planContent.Checks {
+			for _, result := range buildTerraformPlanCheckResults(path, check) {
+				d.StreamListItem(ctx, result)
+			}
+		}
+	} else if pathInfo.IsTFStateFilePath {
+		stateContent, err := getTerraformStateContentFromBytes(path, content)
+		if err != nil {
+			plugin.Logger(ctx).Error("terraform_check_result.listCheckResults", "get_state_content_error", err, "path", path)
+			return nil, err
+		}
+		for _, checkResult := range stateContent.CheckResults {
+			for _, result := range buildTerraformStateCheckResults(path, checkResult) {
+				result.Workspace = pathInfo.Workspace
+				d.StreamListItem(ctx, result)
+			}
+		}
+	}
+
+	return nil, nil
+}
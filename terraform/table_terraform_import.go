@@ -0,0 +1,162 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformImport(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_import",
+		Description: "Terraform import information, i.e., the import blocks of configuration files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listImports,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "to",
+				Description: "The address of the resource instance the object is imported to, e.g. aws_instance.web.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "id",
+				Description: "The import ID of the object. IDs which cannot be evaluated without the rest of the configuration are returned as their source.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("ID"),
+			},
+			{
+				Name:        "for_each",
+				Description: "The collection of the objects imported by the block, if it imports several objects. Expressions which cannot be evaluated without the rest of the configuration are returned as their source.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "provider",
+				Description: "The provider configuration used to import the object, e.g. aws.west, if it is not the default configuration.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "start_line",
+				Description: "Starting line number.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "end_line",
+				Description: "Ending line number.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "source",
+				Description: "The block source code.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformImport struct {
+	To        string
+	ID        string
+	ForEach   interface{}
+	Provider  string
+	StartLine int
+	EndLine   int
+	Source    string
+	Path      string
+}
+
+func listImports(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	data := h.Item.(filePath)
+	path := data.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_import.listImports", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// Return if the path is a TF plan or state path
+	if data.IsTFPlanFilePath || isTerraformPlan(content) || data.IsTFStateFilePath {
+		return nil, nil
+	}
+
+	parser := hclparse.NewParser()
+	file, diags := parseTerraformConfigFile(parser, path, content)
+	if diags.HasErrors() {
+		plugin.Logger(ctx).Error("terraform_import.listImports", "parse_error", diags.Error(), "path", path)
+		return nil, fmt.Errorf("failed to parse file %s: %v", path, diags.Error())
+	}
+
+	fileContent, _, _ := file.Body.PartialContent(terraformSchema)
+	for _, block := range fileContent.Blocks.OfType("import") {
+		d.StreamListItem(ctx, buildTerraformImport(path, content, block))
+	}
+
+	return nil, nil
+}
+
+var terraformImportSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "to"},
+		{Name: "id"},
+		{Name: "for_each"},
+		{Name: "provider"},
+	},
+}
+
+// buildTerraformImport returns the row of an import block
+func buildTerraformImport(path string, content []byte, block *hcl.Block) *terraformImport {
+	tfImport := &terraformImport{
+		Path:      path,
+		StartLine: block.DefRange.Start.Line,
+		EndLine:   block.DefRange.End.Line,
+	}
+	if body, ok := block.Body.(*hclsyntax.Body); ok {
+		tfImport.EndLine = body.SrcRange.End.Line
+		tfImport.Source = string(hcl.RangeBetween(block.DefRange, body.SrcRange).SliceBytes(content))
+	}
+
+	importContent, _, _ := block.Body.PartialContent(terraformImportSchema)
+
+	// The address can refer to each.key when importing several objects with
+	// for_each, e.g. aws_instance.this[each.key], in which case its source, or
+	// its string in the JSON syntax, is returned
+	if attr, ok := importContent.Attributes["to"]; ok {
+		tfImport.To, _ = getTerraformExpressionValue(attr.Expr, content).(string)
+		if traversal, diags := hcl.AbsTraversalForExpr(attr.Expr); !diags.HasErrors() {
+			tfImport.To = renderTraversal(traversal)
+		}
+	}
+	if attr, ok := importContent.Attributes["id"]; ok {
+		tfImport.ID, ok = getTerraformExpressionValue(attr.Expr, content).(string)
+		if !ok {
+			tfImport.ID = string(attr.Expr.Range().SliceBytes(content))
+		}
+	}
+	if attr, ok := importContent.Attributes["for_each"]; ok {
+		tfImport.ForEach = getTerraformExpressionValue(attr.Expr, content)
+	}
+	if attr, ok := importContent.Attributes["provider"]; ok {
+		if traversal, diags := hcl.AbsTraversalForExpr(attr.Expr); !diags.HasErrors() {
+			tfImport.Provider = renderTraversal(traversal)
+		}
+	}
+
+	return tfImport
+}
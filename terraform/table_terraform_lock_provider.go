@@ -0,0 +1,133 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformLockProvider(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_lock_provider",
+		Description: "Terraform lock provider information, i.e., the provider versions recorded in dependency lock files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfLockFileList,
+			Hydrate:       listLockProviders,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "address",
+				Description: "The fully qualified address of the provider, e.g. registry.terraform.io/hashicorp/aws.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "hostname",
+				Description: "The hostname of the registry distributing the provider, e.g. registry.terraform.io.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "namespace",
+				Description: "The namespace of the provider in the registry, e.g. hashicorp.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "The type of the provider, e.g. aws.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "version",
+				Description: "The version of the provider selected by terraform init.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "constraints",
+				Description: "The version constraints of the provider in the configuration when the version was selected, e.g. ~> 5.0.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "version_min",
+				Description: "The lower bound of the versions allowed by the version constraints, e.g. 5.0.0 for ~> 5.0. Use the terraform_version_constraint_check table to get whether the bound is inclusive.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Constraints").Transform(VersionConstraintMin),
+			},
+			{
+				Name:        "version_max",
+				Description: "The upper bound of the versions allowed by the version constraints, e.g. 6.0.0 for ~> 5.0. Use the terraform_version_constraint_check table to get whether the bound is inclusive.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Constraints").Transform(VersionConstraintMax),
+			},
+			{
+				Name:        "hashes",
+				Description: "The checksums of the provider packages accepted for the selected version, with their scheme, either h1 (hash of the package contents for a single platform) or zh (hash of the package archive, as published by the registry).",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "start_line",
+				Description: "Starting line number.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "end_line",
+				Description: "Ending line number.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the dependency lock file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformLockProvider struct {
+	Address     string
+	Hostname    string
+	Namespace   string
+	Type        string
+	Version     string
+	Constraints string
+	Hashes      []terraformLockProviderHash
+	StartLine   int
+	EndLine     int
+	Path        string
+}
+
+type terraformLockProviderHash struct {
+	Scheme string `json:"scheme"`
+	Value  string `json:"value"`
+}
+
+func listLockProviders(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the lock files next to
+	// the config paths or available by the optional key column
+	data := h.Item.(filePath)
+	path := data.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_lock_provider.listLockProviders", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	file, diags := parser.ParseHCL(content, path)
+	if diags.HasErrors() {
+		plugin.Logger(ctx).Error("terraform_lock_provider.listLockProviders", "parse_error", diags.Error(), "path", path)
+		return nil, fmt.Errorf("failed to parse file %s: %v", path, diags.Error())
+	}
+
+	fileContent, _, _ := file.Body.PartialContent(terraformLockSchema)
+	for _, block := range fileContent.Blocks.OfType("provider") {
+		d.StreamListItem(ctx, buildTerraformLockProvider(path, content, block))
+	}
+
+	return nil, nil
+}
@@ -40,6 +40,18 @@ func tableTerraformModule(_ context.Context) *plugin.Table {
 				Description: "Module version",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "version_min",
+				Description: "The lower bound of the versions allowed by the version constraint, e.g. 4.0.0 for ~> 4.0. Use the terraform_version_constraint_check table to get whether the bound is inclusive.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Version").Transform(VersionConstraintMin),
+			},
+			{
+				Name:        "version_max",
+				Description: "The upper bound of the versions allowed by the version constraint, e.g. 5.0.0 for ~> 4.0. Use the terraform_version_constraint_check table to get whether the bound is inclusive.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Version").Transform(VersionConstraintMax),
+			},
 			{
 				Name:        "arguments",
 				Description: "Input arguments passed to this module.",
@@ -3,7 +3,6 @@ package terraform
 import (
 	"context"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 
@@ -20,9 +19,9 @@ func tableTerraformOutput(ctx context.Context) *plugin.Table {
 		Name:        "terraform_output",
 		Description: "Terraform output information.",
 		List: &plugin.ListConfig{
-			ParentHydrate: tfConfigList,
+			ParentHydrate: tfStateList,
 			Hydrate:       listOutputs,
-			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+			KeyColumns:    plugin.OptionalColumns([]string{"path", "workspace"}),
 		},
 		Columns: []*plugin.Column{
 			{
@@ -65,6 +64,11 @@ func tableTerraformOutput(ctx context.Context) *plugin.Table {
 				Description: "The block source code.",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "workspace",
+				Description: "The Terraform CLI workspace of the state file, e.g. default, if the file follows the layout of the local backend, i.e., terraform.tfstate or terraform.tfstate.d/<workspace>/terraform.tfstate.",
+				Type:        proto.ColumnType_STRING,
+			},
 			{
 				Name:        "path",
 				Description: "Path to the file.",
@@ -76,6 +80,7 @@ func tableTerraformOutput(ctx context.Context) *plugin.Table {
 
 type terraformOutput struct {
 	Name        string
+	Workspace   string
 	Path        string
 	StartLine   int
 	EndLine     int
@@ -94,7 +99,7 @@ func listOutputs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData
 	pathInfo := h.Item.(filePath)
 	path := pathInfo.Path
 
-	content, err := os.ReadFile(path)
+	content, err := readStateFileContent(ctx, d, pathInfo)
 	if err != nil {
 		plugin.Logger(ctx).Error("terraform_output.listOutputs", "read_file_error", err, "path", path)
 		return nil, err
@@ -105,70 +110,113 @@ func listOutputs(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData
 		return nil, nil
 	}
 
-	var docs []model.Document
-
 	// Check if the file contains TF state
 	if pathInfo.IsTFStateFilePath {
-		// Initialize the JSON parser
-		jsonParser := p.Parser{}
-
-		// Parse the file content using the JSON parser
-		var str string
-		documents, _, err := jsonParser.Parse(str, content)
-		if err != nil {
-			plugin.Logger(ctx).Error("terraform_output.listOutputs", "state_parse_error", err, "path", path)
-			return nil, fmt.Errorf("failed to parse state file %s: %v", path, err)
-		}
-
-		docs = append(docs, documents...)
-	} else {
-		// Build the terraform parser
-		combinedParser, err := Parser()
+		tfOutputs, err := getTerraformStateOutputs(ctx, pathInfo.HTTPBackendAddress != "", path, content)
 		if err != nil {
-			plugin.Logger(ctx).Error("terraform_output.listOutputs", "create_parser_error", err)
 			return nil, err
 		}
+		for _, tfOutput := range tfOutputs {
+			tfOutput.Workspace = pathInfo.Workspace
+			d.StreamListItem(ctx, tfOutput)
+		}
+		return nil, nil
+	}
 
-		for _, parser := range combinedParser {
-			parsedDocs, err := ParseContent(ctx, d, path, content, parser)
-			if err != nil {
-				plugin.Logger(ctx).Error("terraform_output.listOutputs", "parse_error", err, "path", path)
-				return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
-			}
-			docs = append(docs, parsedDocs.Docs...)
+	var docs []model.Document
+
+	// Build the terraform parser
+	combinedParser, err := Parser()
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_output.listOutputs", "create_parser_error", err)
+		return nil, err
+	}
+
+	for _, parser := range combinedParser {
+		parsedDocs, err := ParseContent(ctx, d, path, content, parser)
+		if err != nil {
+			plugin.Logger(ctx).Error("terraform_output.listOutputs", "parse_error", err, "path", path)
+			return nil, fmt.Errorf("failed to parse file %s: %v", path, err)
 		}
+		docs = append(docs, parsedDocs.Docs...)
 	}
 
 	for _, doc := range docs {
 		if doc["output"] != nil {
 			// For each output, scan its arguments
 			for outputName, outputData := range doc["output"].(model.Document) {
-				tfOutput, err := buildOutput(ctx, pathInfo.IsTFStateFilePath, path, content, outputName, outputData.(model.Document))
+				tfOutput, err := buildOutput(ctx, false, false, path, content, outputName, outputData.(model.Document))
 				if err != nil {
 					plugin.Logger(ctx).Error("terraform_output.listOutputs", "build_output_error", err)
 					return nil, err
 				}
+				tfOutput.Workspace = pathInfo.Workspace
 				d.StreamListItem(ctx, tfOutput)
 			}
-		} else if doc["outputs"] != nil {
-			// For each output, scan its arguments
-			for outputName, outputData := range convertModelDocumentToMapInterface(doc["outputs"]) {
-				if !strings.HasPrefix(outputName, "_kics") {
-					tfOutput, err := buildOutput(ctx, pathInfo.IsTFStateFilePath, path, content, outputName, convertModelDocumentToMapInterface(outputData))
-					if err != nil {
-						plugin.Logger(ctx).Error("terraform_output.listOutputs", "build_output_error", err)
-						return nil, err
-					}
-					d.StreamListItem(ctx, tfOutput)
+		}
+	}
+
+	return nil, nil
+}
+
+// getTerraformStateOutputs returns a row for each root module output recorded
+// in a state file. The lines of the outputs are not located in the states
+// fetched from HTTP backends, as they are not stored in a local file.
+func getTerraformStateOutputs(ctx context.Context, isHTTPBackendState bool, path string, content []byte) ([]terraformOutput, error) {
+	var tfOutputs []terraformOutput
+
+	stateContent, err := getTerraformStateContentFromBytes(path, content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_output.getTerraformStateOutputs", "get_state_content_error", err, "path", path)
+		return nil, err
+	}
+
+	// Legacy states (version 3) record the outputs of the root module within
+	// its module, rather than at the top level
+	if stateContent.IsLegacyState {
+		for outputName, output := range stateContent.Outputs {
+			tfOutput, err := buildTerraformStateOutput(ctx, isHTTPBackendState, path, outputName, output)
+			if err != nil {
+				plugin.Logger(ctx).Error("terraform_output.getTerraformStateOutputs", "build_output_error", err)
+				return nil, err
+			}
+			tfOutputs = append(tfOutputs, tfOutput)
+		}
+		return tfOutputs, nil
+	}
+
+	// Initialize the JSON parser
+	jsonParser := p.Parser{}
+
+	// Parse the file content using the JSON parser
+	var str string
+	documents, _, err := jsonParser.Parse(str, content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_output.getTerraformStateOutputs", "state_parse_error", err, "path", path)
+		return nil, fmt.Errorf("failed to parse state file %s: %v", path, err)
+	}
+
+	for _, doc := range documents {
+		if doc["outputs"] == nil {
+			continue
+		}
+		// For each output, scan its arguments
+		for outputName, outputData := range convertModelDocumentToMapInterface(doc["outputs"]) {
+			if !strings.HasPrefix(outputName, "_kics") {
+				tfOutput, err := buildOutput(ctx, true, isHTTPBackendState, path, content, outputName, convertModelDocumentToMapInterface(outputData))
+				if err != nil {
+					plugin.Logger(ctx).Error("terraform_output.getTerraformStateOutputs", "build_output_error", err)
+					return nil, err
 				}
+				tfOutputs = append(tfOutputs, tfOutput)
 			}
 		}
 	}
 
-	return nil, nil
+	return tfOutputs, nil
 }
 
-func buildOutput(ctx context.Context, isTFStateFilePath bool, path string, content []byte, name string, d model.Document) (terraformOutput, error) {
+func buildOutput(ctx context.Context, isTFStateFilePath bool, isHTTPBackendState bool, path string, content []byte, name string, d model.Document) (terraformOutput, error) {
 	var tfOutput terraformOutput
 
 	tfOutput.Path = path
@@ -178,14 +226,18 @@ func buildOutput(ctx context.Context, isTFStateFilePath bool, path string, conte
 	sanitizeDocument(d)
 
 	if isTFStateFilePath {
-		startLine, endLine, source, err := findBlockLinesFromJSON(ctx, path, "outputs", name)
-		if err != nil {
-			return tfOutput, err
-		}
+		// States fetched from HTTP backends are not stored in a local file, so
+		// the lines of their outputs cannot be located
+		if !isHTTPBackendState {
+			startLine, endLine, source, err := findBlockLinesFromJSON(ctx, path, "outputs", name)
+			if err != nil {
+				return tfOutput, err
+			}
 
-		tfOutput.StartLine = startLine
-		tfOutput.EndLine = endLine
-		tfOutput.Source = source
+			tfOutput.StartLine = startLine
+			tfOutput.EndLine = endLine
+			tfOutput.Source = source
+		}
 	} else {
 		start, end, source, err := getBlock(ctx, path, content, "output", []string{name})
 		if err != nil {
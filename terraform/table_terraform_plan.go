@@ -0,0 +1,154 @@
+package terraform
+
+import (
+	"context"
+	"os"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformPlan(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_plan",
+		Description: "Terraform plan file information.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listPlans,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "format_version",
+				Description: "The version of the JSON plan format. The value will not populate for binary plan files.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "terraform_version",
+				Description: "The version of Terraform which created the plan.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "timestamp",
+				Description: "The time the plan was created.",
+				Type:        proto.ColumnType_TIMESTAMP,
+			},
+			{
+				Name:        "applyable",
+				Description: "True if the plan can be applied. Available in plans created by Terraform 1.8 and later.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Applyable"),
+			},
+			{
+				Name:        "complete",
+				Description: "True if applying the plan is expected to converge the infrastructure with the configuration, i.e. no changes have been deferred. Available in plans created by Terraform 1.8 and later.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Complete"),
+			},
+			{
+				Name:        "errored",
+				Description: "True if the creation of the plan was interrupted by an error.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Errored"),
+			},
+			{
+				Name:        "create_count",
+				Description: "The number of resource instances the plan will create.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("CreateCount"),
+			},
+			{
+				Name:        "update_count",
+				Description: "The number of resource instances the plan will update in-place.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("UpdateCount"),
+			},
+			{
+				Name:        "delete_count",
+				Description: "The number of resource instances the plan will delete.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("DeleteCount"),
+			},
+			{
+				Name:        "replace_count",
+				Description: "The number of resource instances the plan will replace.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("ReplaceCount"),
+			},
+			{
+				Name:        "read_count",
+				Description: "The number of data resource instances the plan will read during apply.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("ReadCount"),
+			},
+			{
+				Name:        "forget_count",
+				Description: "The number of resource instances the plan will remove from the state without destroying them.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("ForgetCount"),
+			},
+			{
+				Name:        "no_op_count",
+				Description: "The number of resource instances the plan will not change.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("NoOpCount"),
+			},
+			{
+				Name:        "relevant_attributes",
+				Description: "The resource attributes which may have contributed to the planned changes, e.g. attributes changed outside of Terraform.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformPlan struct {
+	FormatVersion      string
+	TerraformVersion   string
+	Timestamp          string
+	Applyable          *bool
+	Complete           *bool
+	Errored            *bool
+	CreateCount        int
+	UpdateCount        int
+	DeleteCount        int
+	ReplaceCount       int
+	ReadCount          int
+	ForgetCount        int
+	NoOpCount          int
+	RelevantAttributes []TerraformPlanResourceAttribute
+	Path               string
+}
+
+func listPlans(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan.listPlans", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	if !isTerraformPlan(content) {
+		return nil, nil
+	}
+
+	planContent, err := getTerraformPlanContentFromBytes(content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan.listPlans", "get_plan_content_error", err, "path", path)
+		return nil, err
+	}
+
+	d.StreamListItem(ctx, buildTerraformPlan(path, planContent))
+
+	return nil, nil
+}
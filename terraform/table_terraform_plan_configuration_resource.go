@@ -0,0 +1,137 @@
+package terraform
+
+import (
+	"context"
+	"os"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformPlanConfigurationResource(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_plan_configuration_resource",
+		Description: "Terraform resource configuration information from plan files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listPlanConfigurationResources,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "address",
+				Description: "The absolute address of the resource block, including the address of the module it is declared in.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "module_address",
+				Description: "The address of the module the resource block is declared in, if it is declared in a child module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "mode",
+				Description: "The type of resource Terraform creates, either a resource (managed) or data source (data).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "Resource type.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "name",
+				Description: "Resource name.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "provider_config_key",
+				Description: "The key of the provider configuration used by the resource, e.g. aws or vpc:aws for a provider configuration passed to a module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "expressions",
+				Description: "The expressions of the resource arguments, each with either a constant_value or a list of references.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Expressions").Transform(NullIfEmptyMap),
+			},
+			{
+				Name:        "references",
+				Description: "The unique references found in the expressions of the resource, including the count and for_each expressions.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "count_expression",
+				Description: "The expression of the count meta-argument.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "for_each_expression",
+				Description: "The expression of the for_each meta-argument.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "depends_on",
+				Description: "The explicit dependencies declared with the depends_on meta-argument.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "schema_version",
+				Description: "The version of the resource type schema.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("SchemaVersion"),
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformPlanConfigurationResource struct {
+	Address           string
+	ModuleAddress     string
+	Mode              string
+	Type              string
+	Name              string
+	ProviderConfigKey string
+	Expressions       map[string]interface{}
+	References        []string
+	CountExpression   interface{}
+	ForEachExpression interface{}
+	DependsOn         []string
+	SchemaVersion     int
+	Path              string
+}
+
+func listPlanConfigurationResources(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan_configuration_resource.listPlanConfigurationResources", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// The configuration is only available in TF plan files
+	if !isTerraformPlan(content) {
+		return nil, nil
+	}
+
+	planContent, err := getTerraformPlanContentFromBytes(content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan_configuration_resource.listPlanConfigurationResources", "get_plan_content_error", err, "path", path)
+		return nil, err
+	}
+
+	for _, resource := range getTerraformPlanConfigurationResources("", planContent.Configuration.RootModule) {
+		d.StreamListItem(ctx, buildTerraformPlanConfigurationResource(path, resource))
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,124 @@
+package terraform
+
+import (
+	"context"
+	"os"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformPlanModuleCall(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_plan_module_call",
+		Description: "Terraform module call information from plan files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listPlanModuleCalls,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "name",
+				Description: "Module name.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "address",
+				Description: "The absolute address of the module call, e.g. module.vpc.module.subnets.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "module_address",
+				Description: "The address of the module the module call is declared in, if it is declared in a child module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "module_source",
+				Description: "Module source.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "version_constraint",
+				Description: "The version constraint of the module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "expressions",
+				Description: "The expressions of the input arguments passed to the module, each with either a constant_value or a list of references.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Expressions").Transform(NullIfEmptyMap),
+			},
+			{
+				Name:        "references",
+				Description: "The unique references found in the expressions of the module call, including the count and for_each expressions.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "count_expression",
+				Description: "The expression of the count meta-argument.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "for_each_expression",
+				Description: "The expression of the for_each meta-argument.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "depends_on",
+				Description: "The explicit dependencies declared with the depends_on meta-argument.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformPlanModuleCall struct {
+	Name              string
+	Address           string
+	ModuleAddress     string
+	ModuleSource      string
+	VersionConstraint string
+	Expressions       map[string]interface{}
+	References        []string
+	CountExpression   interface{}
+	ForEachExpression interface{}
+	DependsOn         []string
+	Path              string
+}
+
+func listPlanModuleCalls(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan_module_call.listPlanModuleCalls", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// The configuration is only available in TF plan files
+	if !isTerraformPlan(content) {
+		return nil, nil
+	}
+
+	planContent, err := getTerraformPlanContentFromBytes(content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan_module_call.listPlanModuleCalls", "get_plan_content_error", err, "path", path)
+		return nil, err
+	}
+
+	for _, moduleCall := range getAllTerraformPlanModuleCalls("", planContent.Configuration.RootModule) {
+		d.StreamListItem(ctx, buildTerraformPlanModuleCall(path, moduleCall))
+	}
+
+	return nil, nil
+}
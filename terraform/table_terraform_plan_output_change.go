@@ -0,0 +1,110 @@
+package terraform
+
+import (
+	"context"
+	"os"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+)
+
+func tableTerraformPlanOutputChange(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_plan_output_change",
+		Description: "Terraform output change information from plan files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listPlanOutputChanges,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "name",
+				Description: "Output name.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "actions",
+				Description: "The actions Terraform will take on the output value, e.g. [\"create\"], [\"update\"], [\"delete\"] or [\"no-op\"].",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "sensitive",
+				Description: "True if the output is marked as sensitive.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "before",
+				Description: "The value of the output before the change.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "after",
+				Description: "The value of the output after the change, if known before apply.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "after_unknown",
+				Description: "True if the value of the output will only be known after apply.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "before_sensitive",
+				Description: "True if the output value was sensitive before the change.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "after_sensitive",
+				Description: "True if the output value will be sensitive after the change.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformPlanOutputChange struct {
+	Name            string
+	Actions         []string
+	Sensitive       bool
+	Before          interface{}
+	After           interface{}
+	AfterUnknown    interface{}
+	BeforeSensitive interface{}
+	AfterSensitive  interface{}
+	Path            string
+}
+
+func listPlanOutputChanges(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan_output_change.listPlanOutputChanges", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// Output changes are only available in TF plan files
+	if !isTerraformPlan(content) {
+		return nil, nil
+	}
+
+	planContent, err := getTerraformPlanContentFromBytes(content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan_output_change.listPlanOutputChanges", "get_plan_content_error", err, "path", path)
+		return nil, err
+	}
+
+	for name, change := range planContent.OutputChanges {
+		d.StreamListItem(ctx, buildTerraformPlanOutputChange(path, name, change, planContent.PlannedValues.Outputs[name]))
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"context"
+	"os"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformPlanVariable(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_plan_variable",
+		Description: "Terraform variable values used to create plan files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listPlanVariables,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "name",
+				Description: "The variable name.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "value",
+				Description: "The value of the variable used to create the plan. Values of sensitive variables are returned in plain text, as they are stored in the plan.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Value"),
+			},
+			{
+				Name:        "sensitive",
+				Description: "True if the variable is marked as sensitive in the configuration.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "description",
+				Description: "The description of the variable in the configuration.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformPlanVariable struct {
+	Name        string
+	Value       interface{}
+	Sensitive   bool
+	Description string
+	Path        string
+}
+
+func listPlanVariables(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan_variable.listPlanVariables", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// Variable values are only available in TF plan files
+	if !isTerraformPlan(content) {
+		return nil, nil
+	}
+
+	planContent, err := getTerraformPlanContentFromBytes(content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_plan_variable.listPlanVariables", "get_plan_content_error", err, "path", path)
+		return nil, err
+	}
+
+	for name, variable := range planContent.Variables {
+		d.StreamListItem(ctx, buildTerraformPlanVariable(path, name, variable, planContent.Configuration.RootModule.Variables[name]))
+	}
+
+	return nil, nil
+}
@@ -44,6 +44,18 @@ func tableTerraformProvider(ctx context.Context) *plugin.Table {
 				Description: "The version meta-argument specifies a version constraint for a provider, and works the same way as the version argument in a required_providers block.",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "version_min",
+				Description: "The lower bound of the versions allowed by the version constraint, e.g. 4.0.0 for ~> 4.0. Use the terraform_version_constraint_check table to get whether the bound is inclusive.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Version").Transform(VersionConstraintMin),
+			},
+			{
+				Name:        "version_max",
+				Description: "The upper bound of the versions allowed by the version constraint, e.g. 5.0.0 for ~> 4.0. Use the terraform_version_constraint_check table to get whether the bound is inclusive.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("Version").Transform(VersionConstraintMax),
+			},
 			{
 				Name:        "start_line",
 				Description: "Starting line number.",
@@ -0,0 +1,206 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformRequiredProvider(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_required_provider",
+		Description: "Terraform required provider information, i.e., the entries of the required_providers blocks of configuration files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listRequiredProviders,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "name",
+				Description: "The local name of the provider, used to refer to it in the module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "source",
+				Description: "The source address of the provider, as written in the configuration, e.g. hashicorp/aws.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "hostname",
+				Description: "The hostname of the registry distributing the provider, e.g. registry.terraform.io.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "namespace",
+				Description: "The namespace of the provider in the registry, e.g. hashicorp.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "The type of the provider, e.g. aws.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "version_constraint",
+				Description: "The version constraint of the provider versions which can be used with the module, e.g. ~> 5.0.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "version_min",
+				Description: "The lower bound of the versions allowed by the version constraint, e.g. 5.0.0 for ~> 5.0. Use the terraform_version_constraint_check table to get whether the bound is inclusive.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("VersionConstraint").Transform(VersionConstraintMin),
+			},
+			{
+				Name:        "version_max",
+				Description: "The upper bound of the versions allowed by the version constraint, e.g. 6.0.0 for ~> 5.0. Use the terraform_version_constraint_check table to get whether the bound is inclusive.",
+				Type:        proto.ColumnType_STRING,
+				Transform:   transform.FromField("VersionConstraint").Transform(VersionConstraintMax),
+			},
+			{
+				Name:        "configuration_aliases",
+				Description: "The provider configurations the module expects to be passed by its callers, e.g. aws.west.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "start_line",
+				Description: "Starting line number.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "end_line",
+				Description: "Ending line number.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformRequiredProvider struct {
+	Name                 string
+	Source               string
+	Hostname             string
+	Namespace            string
+	Type                 string
+	VersionConstraint    string
+	ConfigurationAliases []string
+	StartLine            int
+	EndLine              int
+	Path                 string
+}
+
+func listRequiredProviders(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	data := h.Item.(filePath)
+	path := data.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_required_provider.listRequiredProviders", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// Return if the path is a TF plan or state path
+	if data.IsTFPlanFilePath || isTerraformPlan(content) || data.IsTFStateFilePath {
+		return nil, nil
+	}
+
+	parser := hclparse.NewParser()
+	file, diags := parseTerraformConfigFile(parser, path, content)
+	if diags.HasErrors() {
+		plugin.Logger(ctx).Error("terraform_required_provider.listRequiredProviders", "parse_error", diags.Error(), "path", path)
+		return nil, fmt.Errorf("failed to parse file %s: %v", path, diags.Error())
+	}
+
+	fileContent, _, _ := file.Body.PartialContent(terraformSchema)
+	for _, block := range fileContent.Blocks.OfType("terraform") {
+		for _, requiredProvider := range buildTerraformRequiredProviders(path, content, block) {
+			d.StreamListItem(ctx, requiredProvider)
+		}
+	}
+
+	return nil, nil
+}
+
+// buildTerraformRequiredProviders returns a row for each entry of the
+// required_providers blocks of a terraform block
+func buildTerraformRequiredProviders(path string, content []byte, block *hcl.Block) []*terraformRequiredProvider {
+	var requiredProviders []*terraformRequiredProvider
+
+	blockContent, _, _ := block.Body.PartialContent(&hcl.BodySchema{
+		Blocks: []hcl.BlockHeaderSchema{{Type: "required_providers"}},
+	})
+	for _, requiredProvidersBlock := range blockContent.Blocks {
+		attributes, _ := requiredProvidersBlock.Body.JustAttributes()
+		for name, attr := range attributes {
+			requiredProvider := &terraformRequiredProvider{
+				Name:      name,
+				StartLine: attr.Range.Start.Line,
+				EndLine:   attr.Range.End.Line,
+				Path:      path,
+			}
+
+			// Terraform 0.12 only supports a version constraint, e.g. aws = "~> 2.0"
+			items, diags := hcl.ExprMap(attr.Expr)
+			if diags.HasErrors() {
+				requiredProvider.VersionConstraint, _ = getTerraformExpressionValue(attr.Expr, content).(string)
+			} else {
+				for _, item := range items {
+					key := hcl.ExprAsKeyword(item.Key)
+					if key == "" {
+						if value, ok := getTerraformExpressionValue(item.Key, content).(string); ok {
+							key = value
+						}
+					}
+					switch key {
+					case "source":
+						requiredProvider.Source, _ = getTerraformExpressionValue(item.Value, content).(string)
+					case "version":
+						requiredProvider.VersionConstraint, _ = getTerraformExpressionValue(item.Value, content).(string)
+					case "configuration_aliases":
+						// Aliases are references to provider configurations, e.g. aws.west
+						exprs, _ := hcl.ExprList(item.Value)
+						for _, expr := range exprs {
+							if traversal, diags := hcl.AbsTraversalForExpr(expr); !diags.HasErrors() {
+								requiredProvider.ConfigurationAliases = append(requiredProvider.ConfigurationAliases, renderTraversal(traversal))
+							}
+						}
+					}
+				}
+			}
+
+			// Providers without a source default to the hashicorp namespace of the
+			// public registry, using the local name as type. Sources with a type
+			// only, e.g. aws, default to the same namespace.
+			source := requiredProvider.Source
+			if source == "" {
+				source = "hashicorp/" + name
+			} else if !strings.Contains(source, "/") {
+				source = "hashicorp/" + source
+			}
+			requiredProvider.Hostname, requiredProvider.Namespace, requiredProvider.Type = parseProviderSource(source)
+
+			requiredProviders = append(requiredProviders, requiredProvider)
+		}
+	}
+
+	sort.SliceStable(requiredProviders, func(i, j int) bool {
+		return requiredProviders[i].StartLine < requiredProviders[j].StartLine
+	})
+	return requiredProviders
+}
@@ -3,7 +3,6 @@ package terraform
 import (
 	"context"
 	"fmt"
-	"os"
 	"reflect"
 	"strings"
 
@@ -22,9 +21,9 @@ func tableTerraformResource(ctx context.Context) *plugin.Table {
 		Name:        "terraform_resource",
 		Description: "Terraform resource information.",
 		List: &plugin.ListConfig{
-			ParentHydrate: tfConfigList,
+			ParentHydrate: tfStateList,
 			Hydrate:       listResources,
-			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+			KeyColumns:    plugin.OptionalColumns([]string{"path", "workspace", "plan_section"}),
 		},
 		Columns: []*plugin.Column{
 			{
@@ -47,6 +46,22 @@ func tableTerraformResource(ctx context.Context) *plugin.Table {
 				Description: "The absolute resource address.",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "module_address",
+				Description: "The address of the module containing the resource. The value will populate only for the resources that come from a child module in a plan or state file.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "index_key",
+				Description: "The instance key of the resource, either a number for resources created using count or a string for resources created using for_each. The value will populate only for the resources that come from a plan or state file.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("IndexKey"),
+			},
+			{
+				Name:        "plan_section",
+				Description: "The section of the plan file the resource comes from, either planned_values or prior_state. The value will populate only for the resources that come from a plan file. Queries without a plan_section condition only return the planned_values resources: the resources of the prior state are only returned if plan_section = 'prior_state' or plan_section in ('planned_values', 'prior_state') is specified in the where clause.",
+				Type:        proto.ColumnType_STRING,
+			},
 			{
 				Name:        "arguments",
 				Description: "Resource arguments.",
@@ -64,6 +79,44 @@ func tableTerraformResource(ctx context.Context) *plugin.Table {
 				Type:        proto.ColumnType_JSON,
 			},
 
+			// State instance metadata
+			{
+				Name:        "status",
+				Description: "The status of the resource instance, e.g. tainted if the instance must be replaced by the next apply. The value will populate only for the resources that come from a state file.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "deposed",
+				Description: "The deposed key of the resource instance, if the object has been deposed by a create_before_destroy replacement but has not been destroyed yet. The value will populate only for the resources that come from a state file.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "is_deposed",
+				Description: "True if the object has been deposed by a create_before_destroy replacement but has not been destroyed yet. Unlike the deposed column, it is also set for the deposed objects of legacy state files, which have no deposed key. The value will populate only for the resources that come from a state file.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "schema_version",
+				Description: "The version of the provider schema of the resource type the attributes have been recorded with. The value will populate only for the resources that come from a state file.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("SchemaVersion"),
+			},
+			{
+				Name:        "create_before_destroy",
+				Description: "True if the resource instance must be replaced by creating the new object before destroying the old one. The value will populate only for the resources that come from a state file.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "dependencies",
+				Description: "The addresses of the resources the resource instance depends on, as recorded in the state. The value will populate only for the resources that come from a state file.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "sensitive_attributes",
+				Description: "The paths of the attributes of the resource instance marked as sensitive. The value will populate only for the resources that come from a state file.",
+				Type:        proto.ColumnType_JSON,
+			},
+
 			// Meta-arguments
 			{
 				Name:        "count",
@@ -111,6 +164,11 @@ func tableTerraformResource(ctx context.Context) *plugin.Table {
 				Description: "The block source code.",
 				Type:        proto.ColumnType_STRING,
 			},
+			{
+				Name:        "workspace",
+				Description: "The Terraform CLI workspace of the state file, e.g. default, if the file follows the layout of the local backend, i.e., terraform.tfstate or terraform.tfstate.d/<workspace>/terraform.tfstate.",
+				Type:        proto.ColumnType_STRING,
+			},
 			{
 				Name:        "path",
 				Description: "Path to the file.",
@@ -123,6 +181,7 @@ func tableTerraformResource(ctx context.Context) *plugin.Table {
 type terraformResource struct {
 	Name      string
 	Type      string
+	Workspace string
 	Path      string
 	Mode      string
 	StartLine int
@@ -140,6 +199,21 @@ type terraformResource struct {
 	Attributes    interface{}
 	AttributesStd interface{}
 	Address       string
+	ModuleAddress string
+	IndexKey      interface{}
+	PlanSection   string
+	// State instance metadata
+	Status              string
+	Deposed             string
+	IsDeposed           bool
+	SchemaVersion       *int
+	CreateBeforeDestroy bool
+	Dependencies        []interface{}
+	SensitiveAttributes []interface{}
+	// Legacy states record all the attribute values as strings, and no keys
+	// for deposed objects
+	IsLegacyState      bool
+	LegacyDeposedIndex *int
 }
 
 func listResources(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
@@ -149,7 +223,7 @@ func listResources(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 	path := pathInfo.Path
 
 	// Read the content from the file
-	content, err := os.ReadFile(path)
+	content, err := readStateFileContent(ctx, d, pathInfo)
 	if err != nil {
 		plugin.Logger(ctx).Error("terraform_resource.listResources", "read_file_error", err, "path", path)
 		return nil, err
@@ -168,28 +242,37 @@ func listResources(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 			plugin.Logger(ctx).Error("terraform_resource.listResources", "get_plan_content_error", err, "path", path)
 			return nil, err
 		}
-		lookupPath := planContent.PlannedValues.RootModule
 
-		for _, resource := range lookupPath.Resources {
-			tfResource, err := buildTerraformPlanResource(ctx, path, resource)
-			if err != nil {
-				return nil, err
+		// The resources of the prior state are only listed if requested, so that
+		// the resources of the planned values are not duplicated by default. The
+		// prior state is only available if the plan has been created from an
+		// existing state.
+		planSections := getPlanSectionQualValues(d)
+		if planSections["prior_state"] && planContent.PriorState != nil {
+			for _, resource := range getTerraformPlanModuleResources(planContent.PriorState.Values.RootModule) {
+				d.StreamListItem(ctx, buildTerraformPlanPriorStateResource(path, resource))
 			}
+		}
 
-			d.StreamListItem(ctx, tfResource)
+		if planSections["planned_values"] {
+			for _, resource := range getTerraformPlanModuleResources(planContent.PlannedValues.RootModule) {
+				tfResource, err := buildTerraformPlanResource(ctx, path, planContent.IsBinaryPlan, resource)
+				if err != nil {
+					return nil, err
+				}
+
+				d.StreamListItem(ctx, tfResource)
+			}
 		}
 	} else if pathInfo.IsTFStateFilePath { // Check if the file contains TF plan or state
-		// Initialize the JSON parser
-		jsonParser := p.Parser{}
-
-		// Parse the file content using the JSON parser
-		var str string
-		documents, _, err := jsonParser.Parse(str, content)
+		tfResources, err := getTerraformStateResources(ctx, pathInfo.HTTPBackendAddress != "", path, content)
 		if err != nil {
-			plugin.Logger(ctx).Error("terraform_resource.listResources", "parse_error", err, "path", path)
-			return nil, fmt.Errorf("failed to parse plan or state file %s: %v", path, err)
+			return nil, err
+		}
+		for _, tfResource := range tfResources {
+			tfResource.Workspace = pathInfo.Workspace
+			d.StreamListItem(ctx, tfResource)
 		}
-		docs = append(docs, documents...)
 	} else {
 		// Build the terraform parser
 		combinedParser, err := Parser()
@@ -215,7 +298,7 @@ func listResources(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 			for resourceType, resources := range convertModelDocumentToMapInterface(doc["resource"]) {
 				// For each resource, scan its arguments
 				for resourceName, resourceData := range convertModelDocumentToMapInterface(resources) {
-					tfResource, err := buildResource(ctx, pathInfo.IsTFPlanFilePath, content, path, resourceType, resourceName, convertModelDocumentToMapInterface(resourceData))
+					tfResource, err := buildResource(ctx, pathInfo.IsTFPlanFilePath, false, content, path, resourceType, resourceName, convertModelDocumentToMapInterface(resourceData))
 					if err != nil {
 						plugin.Logger(ctx).Error("terraform_resource.listResources", "build_resource_error", err)
 						return nil, err
@@ -230,56 +313,135 @@ func listResources(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateDa
 					d.StreamListItem(ctx, tfResource)
 				}
 			}
-		} else if doc["resources"] != nil { // state file returns resources
-			for _, resource := range doc["resources"].([]interface{}) {
-				resourceData := convertModelDocumentToMapInterface(resource)
-
-				// The property instances contains the configurations of the resource created by terraform
-				// it contains the full configuration, i.e the attributes passed in the config and attributes generated after the resource creation.
-				// The instances attribute can contain more than 1 resource configurations if 'count', 'for_each' or any 'dynamic blocks' has been used.
-				// In that case table should list all the configuration as separate row, as the main intention of the table is to show the terraform configuration per resource.
-				for _, rs := range resourceData["instances"].([]interface{}) {
-					tfResource, err := buildResource(ctx, pathInfo.IsTFStateFilePath, content, path, resourceData["type"].(string), resourceData["name"].(string), resourceData)
-					if err != nil {
-						plugin.Logger(ctx).Error("terraform_resource.listResources", "build_resource_error", err)
-						return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+// getPlanSectionQualValues returns the plan sections requested in the where
+// clause, including each value of an in list. Only the planned values are
+// listed if no plan section is requested.
+func getPlanSectionQualValues(d *plugin.QueryData) map[string]bool {
+	planSections := getEqualsQualStringValues(d, "plan_section")
+	if len(planSections) == 0 {
+		planSections["planned_values"] = true
+	}
+	return planSections
+}
+
+// getTerraformStateResources returns a row for each instance of the resources
+// recorded in a state file. The lines of the resources are not located when
+// skipSourceLookup is set, e.g. for the states fetched from HTTP backends, as
+// they are not stored in a local file.
+func getTerraformStateResources(ctx context.Context, skipSourceLookup bool, path string, content []byte) ([]*terraformResource, error) {
+	var tfResources []*terraformResource
+
+	stateContent, err := getTerraformStateContentFromBytes(path, content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource.getTerraformStateResources", "get_state_content_error", err, "path", path)
+		return nil, err
+	}
+
+	// Legacy states (version 3) have been normalized into the current format
+	if stateContent.IsLegacyState {
+		for _, resource := range stateContent.Resources {
+			for _, instance := range resource.Instances {
+				tfResource := buildTerraformStateResource(path, resource, instance)
+				tfResource.IsLegacyState = true
+				tfResources = append(tfResources, tfResource)
+			}
+		}
+		return tfResources, nil
+	}
+
+	// Initialize the JSON parser
+	jsonParser := p.Parser{}
+
+	// Parse the file content using the JSON parser
+	var str string
+	documents, _, err := jsonParser.Parse(str, content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource.getTerraformStateResources", "parse_error", err, "path", path)
+		return nil, fmt.Errorf("failed to parse state file %s: %v", path, err)
+	}
+
+	for _, doc := range documents {
+		if doc["resources"] == nil {
+			continue
+		}
+		for _, resource := range doc["resources"].([]interface{}) {
+			resourceData := convertModelDocumentToMapInterface(resource)
+
+			// The property instances contains the configurations of the resource created by terraform
+			// it contains the full configuration, i.e the attributes passed in the config and attributes generated after the resource creation.
+			// The instances attribute can contain more than 1 resource configurations if 'count', 'for_each' or any 'dynamic blocks' has been used.
+			// In that case table should list all the configuration as separate row, as the main intention of the table is to show the terraform configuration per resource.
+			// Resources in child modules are recorded with the address of their module, e.g. module.vpc
+			moduleAddress, _ := resourceData["module"].(string)
+
+			for _, rs := range resourceData["instances"].([]interface{}) {
+				tfResource, err := buildResource(ctx, true, skipSourceLookup, content, path, resourceData["type"].(string), resourceData["name"].(string), resourceData)
+				if err != nil {
+					plugin.Logger(ctx).Error("terraform_resource.getTerraformStateResources", "build_resource_error", err)
+					return nil, err
+				}
+				tfResource.ModuleAddress = moduleAddress
+
+				// Extract the value of the 'attributes' property
+				convertedValue := convertModelDocumentToMapInterface(rs)
+				cleanedValue := removeKicsLabels(convertedValue).(map[string]interface{})
+				var indexKey interface{}
+				for property := range cleanedValue {
+					if property == "attributes" {
+						tfResource.Attributes = cleanedValue[property]
 					}
 
-					// Extract the value of the 'attributes' property
-					convertedValue := convertModelDocumentToMapInterface(rs)
-					cleanedValue := removeKicsLabels(convertedValue).(map[string]interface{})
-					for property := range cleanedValue {
-						if property == "attributes" {
-							tfResource.Attributes = cleanedValue[property]
+					// Append the index for unique identification of resources that have been created using "count" (numbers) or "for_each" (strings)
+					if property == "index_key" {
+						switch index := cleanedValue[property].(type) {
+						case float64, string:
+							indexKey = index
 						}
+					}
 
-						// Append the index for unique identification of resources that have been created using "count" or "for_each"
-						if property == "index_key" {
-							if index, ok := cleanedValue[property].(float64); ok {
-								tfResource.Address = fmt.Sprintf("%s.%s[%v]", tfResource.Type, tfResource.Name, index)
-							}
+					// Metadata of the instance, recorded by Terraform rather than by the provider
+					switch property {
+					case "status":
+						tfResource.Status, _ = cleanedValue[property].(string)
+					case "deposed":
+						tfResource.Deposed, _ = cleanedValue[property].(string)
+					case "schema_version":
+						if schemaVersion, ok := cleanedValue[property].(float64); ok {
+							version := int(schemaVersion)
+							tfResource.SchemaVersion = &version
 						}
+					case "create_before_destroy":
+						tfResource.CreateBeforeDestroy, _ = cleanedValue[property].(bool)
+					case "dependencies":
+						tfResource.Dependencies, _ = cleanedValue[property].([]interface{})
+					case "sensitive_attributes":
+						tfResource.SensitiveAttributes, _ = cleanedValue[property].([]interface{})
 					}
+				}
 
-					// Copy the attributes value to attributes_std
-					tfResource.AttributesStd = tfResource.Attributes
+				// Copy the attributes value to attributes_std
+				tfResource.AttributesStd = tfResource.Attributes
+				tfResource.IsDeposed = tfResource.Deposed != ""
 
-					// If the address is empty (resource from terraform config, i.e. .tf files and the terraform plan files)
-					// Form the address string appending the resource type and resource name
-					if tfResource.Address == "" {
-						tfResource.Address = fmt.Sprintf("%s.%s", tfResource.Type, tfResource.Name)
-					}
+				// Form the absolute address of the instance, as printed by "terraform state list"
+				tfResource.IndexKey = indexKey
+				tfResource.Address = buildResourceInstanceAddress(tfResource.ModuleAddress, tfResource.Mode, tfResource.Type, tfResource.Name, indexKey)
 
-					d.StreamListItem(ctx, tfResource)
-				}
+				tfResources = append(tfResources, tfResource)
 			}
 		}
 	}
 
-	return nil, nil
+	return tfResources, nil
 }
 
-func buildResource(ctx context.Context, isTFFilePath bool, content []byte, path string, resourceType string, name string, d model.Document) (*terraformResource, error) {
+func buildResource(ctx context.Context, isTFFilePath bool, skipSourceLookup bool, content []byte, path string, resourceType string, name string, d model.Document) (*terraformResource, error) {
 	tfResource := new(terraformResource)
 
 	tfResource.Path = path
@@ -292,14 +454,19 @@ func buildResource(ctx context.Context, isTFFilePath bool, content []byte, path
 	sanitizeDocument(d)
 
 	if isTFFilePath {
-		startLine, endLine, source, err := findBlockLinesFromJSON(ctx, path, "resources", resourceType, name)
-		if err != nil {
-			return nil, err
-		}
+		if !skipSourceLookup {
+			// Resources in different modules, or managed and data resources, may share the same type and name
+			moduleAddress, _ := d["module"].(string)
+			mode, _ := d["mode"].(string)
+			startLine, endLine, source, err := findBlockLinesFromJSON(ctx, path, "resources", resourceType, name, moduleAddress, mode)
+			if err != nil {
+				return nil, err
+			}
 
-		tfResource.StartLine = startLine
-		tfResource.EndLine = endLine
-		tfResource.Source = source
+			tfResource.StartLine = startLine
+			tfResource.EndLine = endLine
+			tfResource.Source = source
+		}
 	} else {
 		startPosition, endPosition, source, err := getBlock(ctx, path, content, "resource", []string{resourceType, name})
 		if err != nil {
@@ -0,0 +1,166 @@
+package terraform
+
+import (
+	"context"
+	"os"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformResourceChange(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_resource_change",
+		Description: "Terraform resource change information from plan files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listResourceChanges,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "address",
+				Description: "The absolute resource address.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "previous_address",
+				Description: "The previous absolute resource address, if the resource has been moved.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "module_address",
+				Description: "The module portion of the resource address, if the resource is in a child module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "mode",
+				Description: "The type of resource Terraform creates, either a resource (managed) or data source (data).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "Resource type.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "name",
+				Description: "Resource name.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "index",
+				Description: "The instance key for resources created using count or for_each.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Index"),
+			},
+			{
+				Name:        "provider_name",
+				Description: "The fully qualified name of the provider responsible for the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "deposed",
+				Description: "The deposed key, if the change applies to a deposed object.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "actions",
+				Description: "The actions Terraform will take on the object, e.g. [\"create\"], [\"update\"], [\"delete\"], [\"delete\", \"create\"] or [\"no-op\"].",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "action_reason",
+				Description: "Additional context about why the actions were selected, e.g. replace_because_tainted.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "before",
+				Description: "The value of the object before the change.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "after",
+				Description: "The value of the object after the change, excluding any values that are unknown until apply.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "after_unknown",
+				Description: "The attributes of the object whose values will only be known after apply.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "before_sensitive",
+				Description: "The attributes of the object that were sensitive before the change.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "after_sensitive",
+				Description: "The attributes of the object that will be sensitive after the change.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "replace_paths",
+				Description: "The attribute paths that caused the object to be replaced.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformResourceChange struct {
+	Address         string
+	PreviousAddress string
+	ModuleAddress   string
+	Mode            string
+	Type            string
+	Name            string
+	Index           interface{}
+	ProviderName    string
+	Deposed         string
+	Actions         []string
+	ActionReason    string
+	Before          interface{}
+	After           interface{}
+	AfterUnknown    interface{}
+	BeforeSensitive interface{}
+	AfterSensitive  interface{}
+	ReplacePaths    interface{}
+	Path            string
+}
+
+func listResourceChanges(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource_change.listResourceChanges", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// Resource changes are only available in TF plan files
+	if !isTerraformPlan(content) {
+		return nil, nil
+	}
+
+	planContent, err := getTerraformPlanContentFromBytes(content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource_change.listResourceChanges", "get_plan_content_error", err, "path", path)
+		return nil, err
+	}
+
+	for _, change := range planContent.ResourceChanges {
+		d.StreamListItem(ctx, buildTerraformPlanResourceChange(path, change))
+	}
+
+	return nil, nil
+}
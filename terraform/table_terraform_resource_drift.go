@@ -0,0 +1,126 @@
+package terraform
+
+import (
+	"context"
+	"os"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformResourceDrift(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_resource_drift",
+		Description: "Terraform resource drift information from plan files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listResourceDrifts,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "address",
+				Description: "The absolute resource address.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "module_address",
+				Description: "The module portion of the resource address, if the resource is in a child module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "mode",
+				Description: "The type of resource Terraform creates, either a resource (managed) or data source (data).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "Resource type.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "name",
+				Description: "Resource name.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "index",
+				Description: "The instance key for resources created using count or for_each.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("Index"),
+			},
+			{
+				Name:        "provider_name",
+				Description: "The fully qualified name of the provider responsible for the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "deposed",
+				Description: "The deposed key, if the drift applies to a deposed object.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "actions",
+				Description: "The actions describing the change detected outside of Terraform, e.g. [\"update\"] or [\"delete\"].",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "before",
+				Description: "The value of the object as recorded in the prior state.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "after",
+				Description: "The value of the object as found when refreshing, after the changes made outside of Terraform.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "before_sensitive",
+				Description: "The attributes of the object that were sensitive in the prior state.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "after_sensitive",
+				Description: "The attributes of the refreshed object that are sensitive.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+func listResourceDrifts(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource_drift.listResourceDrifts", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// Resource drift is only available in TF plan files
+	if !isTerraformPlan(content) {
+		return nil, nil
+	}
+
+	planContent, err := getTerraformPlanContentFromBytes(content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource_drift.listResourceDrifts", "get_plan_content_error", err, "path", path)
+		return nil, err
+	}
+
+	// The drift entries share the structure of the resource changes
+	for _, drift := range planContent.ResourceDrift {
+		d.StreamListItem(ctx, buildTerraformPlanResourceChange(path, drift))
+	}
+
+	return nil, nil
+}
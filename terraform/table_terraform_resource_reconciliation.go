@@ -0,0 +1,172 @@
+package terraform
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformResourceReconciliation(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_resource_reconciliation",
+		Description: "Reconciliation of the resources declared in Terraform configuration files with the resources recorded in Terraform state files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfStateList,
+			Hydrate:       listResourceReconciliations,
+			KeyColumns:    plugin.OptionalColumns([]string{"path", "workspace", "root"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "address",
+				Description: "The absolute address of the resource instance recorded in the state, e.g. module.vpc.aws_subnet.private[0], or the address of the resource declared in the configuration if the state does not record any instance of it.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "status",
+				Description: "The reconciliation status of the resource. Possible values are: managed (declared in the configuration and recorded in the state), config_only (declared in the configuration only), state_only (recorded in the state only) and orphaned_instance (recorded in the state with an instance key that does not match the count or for_each of the configuration).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "config_address",
+				Description: "The address of the resource in the configuration, without any instance keys, e.g. module.vpc.aws_subnet.private.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "mode",
+				Description: "The type of resource Terraform creates, either a resource (managed) or data source (data).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "Resource type.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "name",
+				Description: "Resource name.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "module_address",
+				Description: "The address of the module containing the resource, e.g. module.vpc. Empty for resources in the root module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "index_key",
+				Description: "The key of the instance recorded in the state, for resources created using count (a number) or for_each (a string).",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("IndexKey"),
+			},
+			{
+				Name:        "deposed",
+				Description: "The key of the deposed object, if the instance recorded in the state is a deposed object pending destruction.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "is_deposed",
+				Description: "True if the instance recorded in the state is a deposed object pending destruction. Unlike the deposed column, it is also set for the deposed objects of legacy state files, which have no deposed key.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "config_path",
+				Description: "Path to the configuration file declaring the resource.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "start_line",
+				Description: "Starting line number of the resource block in the configuration file.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "end_line",
+				Description: "Ending line number of the resource block in the configuration file.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "root",
+				Description: "Path to the directory of the root module the state file is reconciled with. Defaults to the directory containing the state file, or the parent directory of terraform.tfstate.d for the states of workspaces, unless specified in the where clause.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "workspace",
+				Description: "The Terraform CLI workspace of the state file, e.g. default, if the file follows the layout of the local backend, i.e., terraform.tfstate or terraform.tfstate.d/<workspace>/terraform.tfstate.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the state file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformResourceReconciliation struct {
+	Address       string
+	Status        string
+	ConfigAddress string
+	Mode          string
+	Type          string
+	Name          string
+	ModuleAddress string
+	IndexKey      interface{}
+	Deposed       string
+	IsDeposed     bool
+	ConfigPath    string
+	StartLine     int
+	EndLine       int
+	Root          string
+	Workspace     string
+	Path          string
+}
+
+func listResourceReconciliations(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	// The table only lists TF state files, which are reconciled with the
+	// configuration of their root module
+	if !pathInfo.IsTFStateFilePath {
+		return nil, nil
+	}
+
+	// The root module defaults to the directory the state file is stored in.
+	// The states fetched from HTTP backends are not stored with any
+	// configuration, so they are only reconciled with the requested root.
+	root := d.EqualsQualString("root")
+	if root == "" {
+		if pathInfo.HTTPBackendAddress != "" {
+			return nil, nil
+		}
+		root = getStateFileRootDir(path)
+	}
+
+	content, err := readStateFileContent(ctx, d, pathInfo)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource_reconciliation.listResourceReconciliations", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	stateResources, err := getTerraformStateResources(ctx, pathInfo.HTTPBackendAddress != "", path, content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource_reconciliation.listResourceReconciliations", "get_state_resources_error", err, "path", path)
+		return nil, err
+	}
+
+	config, err := getTerraformConfig(root)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_resource_reconciliation.listResourceReconciliations", "get_config_error", err, "root", root)
+		return nil, err
+	}
+
+	for _, reconciliation := range buildTerraformResourceReconciliations(root, path, config, stateResources) {
+		reconciliation.Workspace = pathInfo.Workspace
+		d.StreamListItem(ctx, reconciliation)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,189 @@
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
+)
+
+func TestGetPlanSectionQualValues(t *testing.T) {
+	stringValue := func(s string) *proto.QualValue {
+		return &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: s}}
+	}
+	listValue := func(values ...string) *proto.QualValue {
+		list := &proto.QualValueList{}
+		for _, value := range values {
+			list.Values = append(list.Values, stringValue(value))
+		}
+		return &proto.QualValue{Value: &proto.QualValue_ListValue{ListValue: list}}
+	}
+
+	tests := []struct {
+		name  string
+		quals quals.QualSlice
+		want  map[string]bool
+	}{
+		{
+			name: "no qual",
+			want: map[string]bool{"planned_values": true},
+		},
+		{
+			name:  "equal qual",
+			quals: quals.QualSlice{{Column: "plan_section", Operator: quals.QualOperatorEqual, Value: stringValue("prior_state")}},
+			want:  map[string]bool{"prior_state": true},
+		},
+		{
+			name:  "in qual",
+			quals: quals.QualSlice{{Column: "plan_section", Operator: quals.QualOperatorEqual, Value: listValue("planned_values", "prior_state")}},
+			want:  map[string]bool{"planned_values": true, "prior_state": true},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &plugin.QueryData{Quals: plugin.KeyColumnQualMap{}}
+			if test.quals != nil {
+				d.Quals["plan_section"] = &plugin.KeyColumnQuals{Name: "plan_section", Quals: test.quals}
+			}
+			if got := getPlanSectionQualValues(d); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got plan sections %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetTerraformStateResourcesLines(t *testing.T) {
+	const state = `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "serial": 1,
+  "lineage": "test",
+  "outputs": {},
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "a",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "schema_version": 0,
+          "attributes": {"bucket": "root"}
+        }
+      ]
+    },
+    {
+      "module": "module.m[0]",
+      "mode": "data",
+      "type": "aws_s3_bucket",
+      "name": "a",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "index_key": 0,
+          "schema_version": 0,
+          "attributes": {"bucket": "module-0"}
+        },
+        {
+          "index_key": 1,
+          "schema_version": 0,
+          "attributes": {"bucket": "module-1"}
+        }
+      ]
+    }
+  ],
+  "check_results": null
+}
+`
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	if err := os.WriteFile(path, []byte(state), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := getTerraformStateResources(context.Background(), false, path, []byte(state))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][2]int{
+		"aws_s3_bucket.a":                     {8, 19},
+		"module.m[0].data.aws_s3_bucket.a[0]": {20, 38},
+		"module.m[0].data.aws_s3_bucket.a[1]": {20, 38},
+	}
+	if len(resources) != len(want) {
+		t.Fatalf("got %d resources, want %d", len(resources), len(want))
+	}
+	for _, resource := range resources {
+		lines, ok := want[resource.Address]
+		if !ok {
+			t.Errorf("unexpected resource %s", resource.Address)
+			continue
+		}
+		if resource.StartLine != lines[0] || resource.EndLine != lines[1] {
+			t.Errorf("got lines %d-%d for %s, want %d-%d", resource.StartLine, resource.EndLine, resource.Address, lines[0], lines[1])
+		}
+		wantModule := `"module": "` + resource.ModuleAddress + `"`
+		if resource.ModuleAddress != "" && !strings.Contains(resource.Source, wantModule) {
+			t.Errorf("got source of another resource for %s:\n%s", resource.Address, resource.Source)
+		}
+		if resource.ModuleAddress == "" && strings.Contains(resource.Source, `"module"`) {
+			t.Errorf("got source of another resource for %s:\n%s", resource.Address, resource.Source)
+		}
+	}
+}
+
+func TestGetEqualsQualStringValues(t *testing.T) {
+	stringValue := func(s string) *proto.QualValue {
+		return &proto.QualValue{Value: &proto.QualValue_StringValue{StringValue: s}}
+	}
+	listValue := func(values ...string) *proto.QualValue {
+		list := &proto.QualValueList{}
+		for _, value := range values {
+			list.Values = append(list.Values, stringValue(value))
+		}
+		return &proto.QualValue{Value: &proto.QualValue_ListValue{ListValue: list}}
+	}
+
+	tests := []struct {
+		name  string
+		quals quals.QualSlice
+		want  map[string]bool
+	}{
+		{
+			name: "no qual",
+			want: map[string]bool{},
+		},
+		{
+			name:  "equal qual",
+			quals: quals.QualSlice{{Column: "workspace", Operator: quals.QualOperatorEqual, Value: stringValue("prod")}},
+			want:  map[string]bool{"prod": true},
+		},
+		{
+			name:  "in qual",
+			quals: quals.QualSlice{{Column: "workspace", Operator: quals.QualOperatorEqual, Value: listValue("dev", "prod")}},
+			want:  map[string]bool{"dev": true, "prod": true},
+		},
+		{
+			name:  "other operator",
+			quals: quals.QualSlice{{Column: "workspace", Operator: quals.QualOperatorNotEqual, Value: stringValue("dev")}},
+			want:  map[string]bool{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d := &plugin.QueryData{Quals: plugin.KeyColumnQualMap{}}
+			if test.quals != nil {
+				d.Quals["workspace"] = &plugin.KeyColumnQuals{Name: "workspace", Quals: test.quals}
+			}
+			if got := getEqualsQualStringValues(d, "workspace"); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got workspaces %v, want %v", got, test.want)
+			}
+		})
+	}
+}
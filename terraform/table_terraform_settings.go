@@ -0,0 +1,232 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformSettings(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_settings",
+		Description: "Terraform settings information, i.e., the terraform blocks of configuration files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfConfigList,
+			Hydrate:       listSettings,
+			KeyColumns:    plugin.OptionalColumns([]string{"path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "required_version",
+				Description: "The version constraint of the Terraform versions which can be used with the configuration.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "experiments",
+				Description: "The experimental language features enabled for the module.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "backend_type",
+				Description: "The type of the backend storing the state, e.g. s3.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "backend_arguments",
+				Description: "The arguments of the backend. Arguments which cannot be evaluated without the rest of the configuration are returned as their source.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("BackendArguments").Transform(NullIfEmptyMap),
+			},
+			{
+				Name:        "cloud_organization",
+				Description: "The HCP Terraform or Terraform Enterprise organization of the cloud block.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "cloud_hostname",
+				Description: "The hostname of the Terraform Enterprise instance of the cloud block.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "cloud_workspaces",
+				Description: "The workspaces of the cloud block, selected either by name, or by tags and project.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "provider_meta",
+				Description: "The metadata passed to each provider, keyed by provider name.",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("ProviderMeta").Transform(NullIfEmptyMap),
+			},
+			{
+				Name:        "start_line",
+				Description: "Starting line number.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "end_line",
+				Description: "Ending line number.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "source",
+				Description: "The block source code.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformSettings struct {
+	RequiredVersion   string
+	Experiments       []string
+	BackendType       string
+	BackendArguments  map[string]interface{}
+	CloudOrganization string
+	CloudHostname     string
+	CloudWorkspaces   interface{}
+	ProviderMeta      map[string]interface{}
+	StartLine         int
+	EndLine           int
+	Source            string
+	Path              string
+}
+
+func listSettings(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	data := h.Item.(filePath)
+	path := data.Path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_settings.listSettings", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	// Return if the path is a TF plan or state path
+	if data.IsTFPlanFilePath || isTerraformPlan(content) || data.IsTFStateFilePath {
+		return nil, nil
+	}
+
+	parser := hclparse.NewParser()
+	file, diags := parseTerraformConfigFile(parser, path, content)
+	if diags.HasErrors() {
+		plugin.Logger(ctx).Error("terraform_settings.listSettings", "parse_error", diags.Error(), "path", path)
+		return nil, fmt.Errorf("failed to parse file %s: %v", path, diags.Error())
+	}
+
+	// A file can contain several terraform blocks, which are merged by Terraform
+	fileContent, _, _ := file.Body.PartialContent(terraformSchema)
+	for _, block := range fileContent.Blocks.OfType("terraform") {
+		d.StreamListItem(ctx, buildTerraformSettings(path, content, block))
+	}
+
+	return nil, nil
+}
+
+// buildTerraformSettings returns the row of a terraform block of a
+// configuration file
+func buildTerraformSettings(path string, content []byte, block *hcl.Block) *terraformSettings {
+	tfSettings := &terraformSettings{
+		Path:      path,
+		StartLine: block.DefRange.Start.Line,
+		EndLine:   block.DefRange.End.Line,
+	}
+	if body, ok := block.Body.(*hclsyntax.Body); ok {
+		tfSettings.EndLine = body.SrcRange.End.Line
+		tfSettings.Source = string(hcl.RangeBetween(block.DefRange, body.SrcRange).SliceBytes(content))
+	}
+
+	attributes, _ := block.Body.JustAttributes()
+	if attr, ok := attributes["required_version"]; ok {
+		if value, ok := getTerraformExpressionValue(attr.Expr, content).(string); ok {
+			tfSettings.RequiredVersion = value
+		}
+	}
+
+	// Experiments are keywords, rather than strings
+	if attr, ok := attributes["experiments"]; ok {
+		exprs, _ := hcl.ExprList(attr.Expr)
+		for _, expr := range exprs {
+			if traversal, diags := hcl.AbsTraversalForExpr(expr); !diags.HasErrors() {
+				tfSettings.Experiments = append(tfSettings.Experiments, traversal.RootName())
+			}
+		}
+	}
+
+	settingsContent, _, _ := block.Body.PartialContent(terraformSettingsSchema)
+	for _, nestedBlock := range settingsContent.Blocks {
+		switch nestedBlock.Type {
+		case "backend":
+			tfSettings.BackendType = nestedBlock.Labels[0]
+			tfSettings.BackendArguments = getTerraformBlockArguments(nestedBlock.Body, content)
+
+		case "cloud":
+			arguments := getTerraformBlockArguments(nestedBlock.Body, content)
+			tfSettings.CloudOrganization, _ = arguments["organization"].(string)
+			tfSettings.CloudHostname, _ = arguments["hostname"].(string)
+			// The workspaces block is decoded as an object in the JSON syntax
+			switch workspaces := arguments["workspaces"].(type) {
+			case []interface{}:
+				if len(workspaces) > 0 {
+					tfSettings.CloudWorkspaces = workspaces[0]
+				}
+			case map[string]interface{}:
+				tfSettings.CloudWorkspaces = workspaces
+			}
+
+		case "provider_meta":
+			if tfSettings.ProviderMeta == nil {
+				tfSettings.ProviderMeta = map[string]interface{}{}
+			}
+			tfSettings.ProviderMeta[nestedBlock.Labels[0]] = getTerraformBlockArguments(nestedBlock.Body, content)
+		}
+	}
+
+	return tfSettings
+}
+
+var terraformSettingsSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "backend", LabelNames: []string{"type"}},
+		{Type: "cloud"},
+		{Type: "provider_meta", LabelNames: []string{"provider"}},
+	},
+}
+
+// getTerraformBlockArguments returns the arguments of a block, along with its
+// nested blocks as lists of arguments, keyed by the block type
+func getTerraformBlockArguments(body hcl.Body, content []byte) map[string]interface{} {
+	arguments := map[string]interface{}{}
+
+	syntaxBody, ok := body.(*hclsyntax.Body)
+	if !ok {
+		attributes, _ := body.JustAttributes()
+		for name, attr := range attributes {
+			arguments[name] = getTerraformExpressionValue(attr.Expr, content)
+		}
+		return arguments
+	}
+
+	for name, attr := range syntaxBody.Attributes {
+		arguments[name] = getTerraformExpressionValue(attr.Expr, content)
+	}
+	for _, block := range syntaxBody.Blocks {
+		blocks, _ := arguments[block.Type].([]interface{})
+		arguments[block.Type] = append(blocks, getTerraformBlockArguments(block.Body, content))
+	}
+	return arguments
+}
@@ -0,0 +1,121 @@
+package terraform
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformState(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_state",
+		Description: "Terraform state file information.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfStateList,
+			Hydrate:       listStates,
+			KeyColumns:    plugin.OptionalColumns([]string{"path", "workspace"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "lineage",
+				Description: "The unique ID assigned to the state when it was created. States with different lineages are unrelated.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "serial",
+				Description: "The serial number of the state, incremented each time the state is written.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("Serial"),
+			},
+			{
+				Name:        "version",
+				Description: "The version of the state file format.",
+				Type:        proto.ColumnType_INT,
+			},
+			{
+				Name:        "terraform_version",
+				Description: "The version of Terraform which last wrote the state.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "resource_count",
+				Description: "The number of managed resources recorded in the state.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("ResourceCount"),
+			},
+			{
+				Name:        "data_resource_count",
+				Description: "The number of data resources recorded in the state.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("DataResourceCount"),
+			},
+			{
+				Name:        "instance_count",
+				Description: "The number of instances of all the managed and data resources recorded in the state.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("InstanceCount"),
+			},
+			{
+				Name:        "output_count",
+				Description: "The number of root module outputs recorded in the state.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("OutputCount"),
+			},
+			{
+				Name:        "workspace",
+				Description: "The Terraform CLI workspace of the state file, e.g. default, if the file follows the layout of the local backend, i.e., terraform.tfstate or terraform.tfstate.d/<workspace>/terraform.tfstate.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformState struct {
+	Lineage           string
+	Serial            int64
+	Version           int
+	TerraformVersion  string
+	ResourceCount     int
+	DataResourceCount int
+	InstanceCount     int
+	OutputCount       int
+	Workspace         string
+	Path              string
+}
+
+func listStates(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	// The table only lists TF state files
+	if !pathInfo.IsTFStateFilePath {
+		return nil, nil
+	}
+
+	content, err := readStateFileContent(ctx, d, pathInfo)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_state.listStates", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	stateContent, err := getTerraformStateContentFromBytes(path, content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_state.listStates", "get_state_content_error", err, "path", path)
+		return nil, err
+	}
+
+	tfState := buildTerraformState(path, stateContent)
+	tfState.Workspace = pathInfo.Workspace
+	d.StreamListItem(ctx, tfState)
+
+	return nil, nil
+}
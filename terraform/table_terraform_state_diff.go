@@ -0,0 +1,145 @@
+package terraform
+
+import (
+	"context"
+	"os"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformStateDiff(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_state_diff",
+		Description: "Differences between the resources recorded in two Terraform state files.",
+		List: &plugin.ListConfig{
+			Hydrate:    listStateDiffs,
+			KeyColumns: plugin.AllColumns([]string{"left_path", "right_path"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "address",
+				Description: "The absolute address of the resource instance, e.g. module.vpc.aws_subnet.private[0].",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "change",
+				Description: "The change of the resource instance from the left state to the right state. Possible values are: added, removed and changed.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "mode",
+				Description: "The type of resource Terraform creates, either a resource (managed) or data source (data).",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "Resource type.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "name",
+				Description: "Resource name.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "module_address",
+				Description: "The address of the module containing the resource, e.g. module.vpc. Empty for resources in the root module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "index_key",
+				Description: "The key of the instance, for resources created using count (a number) or for_each (a string).",
+				Type:        proto.ColumnType_JSON,
+				Transform:   transform.FromField("IndexKey"),
+			},
+			{
+				Name:        "deposed",
+				Description: "The key of the deposed object, if the instance is a deposed object pending destruction.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "is_deposed",
+				Description: "True if the instance is a deposed object pending destruction. Unlike the deposed column, it is also set for the deposed objects of legacy state files, which have no deposed key.",
+				Type:        proto.ColumnType_BOOL,
+			},
+			{
+				Name:        "attribute_diff",
+				Description: "The attribute values which differ between the left and right states, each with its path, e.g. tags.Name, and its left and right value. Nested objects and lists of the same length are compared element by element.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "left_attributes",
+				Description: "The attributes of the resource instance in the left state.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "right_attributes",
+				Description: "The attributes of the resource instance in the right state.",
+				Type:        proto.ColumnType_JSON,
+			},
+			{
+				Name:        "left_path",
+				Description: "Path to the state file to compare from.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "right_path",
+				Description: "Path to the state file to compare to.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformStateDiff struct {
+	Address         string
+	Change          string
+	Mode            string
+	Type            string
+	Name            string
+	ModuleAddress   string
+	IndexKey        interface{}
+	Deposed         string
+	IsDeposed       bool
+	AttributeDiff   []terraformStateAttributeDiff
+	LeftAttributes  interface{}
+	RightAttributes interface{}
+	LeftPath        string
+	RightPath       string
+}
+
+type terraformStateAttributeDiff struct {
+	Attribute string      `json:"attribute"`
+	Left      interface{} `json:"left"`
+	Right     interface{} `json:"right"`
+}
+
+func listStateDiffs(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	leftPath := d.EqualsQualString("left_path")
+	rightPath := d.EqualsQualString("right_path")
+
+	var states [2][]*terraformResource
+	for i, path := range []string{leftPath, rightPath} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			plugin.Logger(ctx).Error("terraform_state_diff.listStateDiffs", "read_file_error", err, "path", path)
+			return nil, err
+		}
+
+		// Diffs have no lines or source, so the resources are not located in the file
+		tfResources, err := getTerraformStateResources(ctx, true, path, content)
+		if err != nil {
+			plugin.Logger(ctx).Error("terraform_state_diff.listStateDiffs", "get_state_resources_error", err, "path", path)
+			return nil, err
+		}
+		states[i] = tfResources
+	}
+
+	for _, diff := range buildTerraformStateDiffs(leftPath, rightPath, states[0], states[1]) {
+		d.StreamListItem(ctx, diff)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,125 @@
+package terraform
+
+import (
+	"context"
+
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformStateProvider(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_state_provider",
+		Description: "Terraform provider configurations referenced by state files.",
+		List: &plugin.ListConfig{
+			ParentHydrate: tfStateList,
+			Hydrate:       listStateProviders,
+			KeyColumns:    plugin.OptionalColumns([]string{"path", "workspace"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "address",
+				Description: "The absolute address of the provider configuration, e.g. provider[\"registry.terraform.io/hashicorp/aws\"].west.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "module_address",
+				Description: "The address of the module containing the provider configuration, if the provider is configured in a child module.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "source",
+				Description: "The source address of the provider, e.g. registry.terraform.io/hashicorp/aws.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "hostname",
+				Description: "The hostname of the registry the provider is installed from, e.g. registry.terraform.io.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "namespace",
+				Description: "The namespace of the provider in the registry, e.g. hashicorp.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "type",
+				Description: "The type of the provider, e.g. aws.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "alias",
+				Description: "The alias of the provider configuration, if any.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "resource_count",
+				Description: "The number of resources managed or read using the provider configuration.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("ResourceCount"),
+			},
+			{
+				Name:        "instance_count",
+				Description: "The number of resource instances managed or read using the provider configuration.",
+				Type:        proto.ColumnType_INT,
+				Transform:   transform.FromField("InstanceCount"),
+			},
+			{
+				Name:        "workspace",
+				Description: "The Terraform CLI workspace of the state file, e.g. default, if the file follows the layout of the local backend, i.e., terraform.tfstate or terraform.tfstate.d/<workspace>/terraform.tfstate.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "path",
+				Description: "Path to the file.",
+				Type:        proto.ColumnType_STRING,
+			},
+		},
+	}
+}
+
+type terraformStateProvider struct {
+	Address       string
+	ModuleAddress string
+	Source        string
+	Hostname      string
+	Namespace     string
+	Type          string
+	Alias         string
+	ResourceCount int
+	InstanceCount int
+	Workspace     string
+	Path          string
+}
+
+func listStateProviders(ctx context.Context, d *plugin.QueryData, h *plugin.HydrateData) (interface{}, error) {
+	// The path comes from a parent hydrate, defaulting to the config paths or
+	// available by the optional key column
+	pathInfo := h.Item.(filePath)
+	path := pathInfo.Path
+
+	// The table only lists the providers of TF state files
+	if !pathInfo.IsTFStateFilePath {
+		return nil, nil
+	}
+
+	content, err := readStateFileContent(ctx, d, pathInfo)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_state_provider.listStateProviders", "read_file_error", err, "path", path)
+		return nil, err
+	}
+
+	stateContent, err := getTerraformStateContentFromBytes(path, content)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_state_provider.listStateProviders", "get_state_content_error", err, "path", path)
+		return nil, err
+	}
+
+	for _, tfProvider := range buildTerraformStateProviders(path, stateContent) {
+		tfProvider.Workspace = pathInfo.Workspace
+		d.StreamListItem(ctx, tfProvider)
+	}
+
+	return nil, nil
+}
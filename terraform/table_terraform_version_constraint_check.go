@@ -0,0 +1,120 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/turbot/steampipe-plugin-sdk/v5/grpc/proto"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+func tableTerraformVersionConstraintCheck(ctx context.Context) *plugin.Table {
+	return &plugin.Table{
+		Name:        "terraform_version_constraint_check",
+		Description: "Evaluation of a Terraform version constraint against a version.",
+		List: &plugin.ListConfig{
+			Hydrate:    listVersionConstraintChecks,
+			KeyColumns: plugin.AllColumns([]string{"constraint", "version"}),
+		},
+		Columns: []*plugin.Column{
+			{
+				Name:        "constraint",
+				Description: "The version constraint, e.g. ~> 4.0.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "version",
+				Description: "The version checked against the constraint, e.g. 4.67.0.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "allowed",
+				Description: "True if the version is allowed by the constraint.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("Allowed"),
+			},
+			{
+				Name:        "min_version",
+				Description: "The lower bound of the versions allowed by the constraint, e.g. 4.0.0 for ~> 4.0. Null if the constraint has no lower bound.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "min_inclusive",
+				Description: "True if the lower bound is allowed by the constraint, e.g. true for >= 4.0 and false for > 4.0.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("MinInclusive"),
+			},
+			{
+				Name:        "max_version",
+				Description: "The upper bound of the versions allowed by the constraint, e.g. 5.0.0 for ~> 4.0. Null if the constraint has no upper bound.",
+				Type:        proto.ColumnType_STRING,
+			},
+			{
+				Name:        "max_inclusive",
+				Description: "True if the upper bound is allowed by the constraint, e.g. false for ~> 4.0 and true for <= 4.67.0.",
+				Type:        proto.ColumnType_BOOL,
+				Transform:   transform.FromField("MaxInclusive"),
+			},
+		},
+	}
+}
+
+type terraformVersionConstraintCheck struct {
+	Constraint   string
+	Version      string
+	Allowed      bool
+	MinVersion   *string
+	MinInclusive *bool
+	MaxVersion   *string
+	MaxInclusive *bool
+}
+
+func listVersionConstraintChecks(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+	constraintString := d.EqualsQualString("constraint")
+	versionString := d.EqualsQualString("version")
+
+	check, err := buildTerraformVersionConstraintCheck(constraintString, versionString)
+	if err != nil {
+		plugin.Logger(ctx).Error("terraform_version_constraint_check.listVersionConstraintChecks", "build_check_error", err, "constraint", constraintString, "version", versionString)
+		return nil, err
+	}
+	d.StreamListItem(ctx, check)
+
+	return nil, nil
+}
+
+// buildTerraformVersionConstraintCheck evaluates a version constraint against
+// a version. The bounds of an unbounded range are left nil.
+func buildTerraformVersionConstraintCheck(constraintString string, versionString string) (*terraformVersionConstraintCheck, error) {
+	constraint, err := version.NewConstraint(constraintString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version constraint %s: %v", constraintString, err)
+	}
+	v, err := version.NewVersion(versionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse version %s: %v", versionString, err)
+	}
+	bounds, err := getVersionConstraintBounds(constraintString)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &terraformVersionConstraintCheck{
+		Constraint: constraintString,
+		Version:    versionString,
+		Allowed:    constraint.Check(v),
+	}
+	if bounds.Min != nil {
+		minVersion := bounds.Min.String()
+		check.MinVersion = &minVersion
+		check.MinInclusive = &bounds.MinInclusive
+	}
+	if bounds.Max != nil {
+		maxVersion := bounds.Max.String()
+		check.MaxVersion = &maxVersion
+		check.MaxInclusive = &bounds.MaxInclusive
+	}
+	return check, nil
+}
@@ -0,0 +1,80 @@
+package terraform
+
+import "testing"
+
+func TestBuildTerraformVersionConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint   string
+		version      string
+		allowed      bool
+		minVersion   *string
+		minInclusive *bool
+		maxVersion   *string
+		maxInclusive *bool
+	}{
+		{
+			constraint:   ">= 1.5",
+			version:      "1.9.0",
+			allowed:      true,
+			minVersion:   stringPointer("1.5.0"),
+			minInclusive: boolPointer(true),
+		},
+		{
+			constraint:   "< 2.0",
+			version:      "2.1.0",
+			allowed:      false,
+			maxVersion:   stringPointer("2.0.0"),
+			maxInclusive: boolPointer(false),
+		},
+		{
+			constraint:   "~> 4.0",
+			version:      "4.67.0",
+			allowed:      true,
+			minVersion:   stringPointer("4.0.0"),
+			minInclusive: boolPointer(true),
+			maxVersion:   stringPointer("5.0.0"),
+			maxInclusive: boolPointer(false),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			check, err := buildTerraformVersionConstraintCheck(test.constraint, test.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Allowed != test.allowed {
+				t.Errorf("got allowed %v, want %v", check.Allowed, test.allowed)
+			}
+			assertStringPointer(t, "min_version", check.MinVersion, test.minVersion)
+			assertBoolPointer(t, "min_inclusive", check.MinInclusive, test.minInclusive)
+			assertStringPointer(t, "max_version", check.MaxVersion, test.maxVersion)
+			assertBoolPointer(t, "max_inclusive", check.MaxInclusive, test.maxInclusive)
+		})
+	}
+}
+
+func stringPointer(s string) *string { return &s }
+
+func boolPointer(b bool) *bool { return &b }
+
+func assertStringPointer(t *testing.T, name string, got *string, want *string) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("got %s %v, want %v", name, got, want)
+	case *got != *want:
+		t.Errorf("got %s %s, want %s", name, *got, *want)
+	}
+}
+
+func assertBoolPointer(t *testing.T, name string, got *bool, want *bool) {
+	t.Helper()
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("got %s %v, want %v", name, got, want)
+	case *got != *want:
+		t.Errorf("got %s %v, want %v", name, *got, *want)
+	}
+}
@@ -8,10 +8,12 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
-	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/Checkmarx/kics/pkg/model"
 	"github.com/Checkmarx/kics/pkg/parser"
@@ -20,7 +22,9 @@ import (
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/quals"
 	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+	"github.com/zclconf/go-cty/cty"
 	ctyjson "github.com/zclconf/go-cty/cty/json"
 
 	filehelpers "github.com/turbot/go-kit/files"
@@ -30,8 +34,30 @@ type filePath struct {
 	Path              string
 	IsTFPlanFilePath  bool
 	IsTFStateFilePath bool
+	// The Terraform CLI workspace of a state file, if it can be derived from
+	// the layout of the local backend
+	Workspace string
+	// The address of the HTTP backend a state is fetched from, in which case
+	// the path is the address as well
+	HTTPBackendAddress string
 }
 
+// The local backend stores the state of the default workspace in
+// terraform.tfstate, and the state of other workspaces in
+// terraform.tfstate.d/<workspace>/terraform.tfstate. terraform init creates
+// the .terraform directory next to them.
+const (
+	defaultWorkspaceName    = "default"
+	localDataDirName        = ".terraform"
+	localStateFileName      = "terraform.tfstate"
+	localWorkspacesDirName  = "terraform.tfstate.d"
+	localWorkspaceStateGlob = "terraform.tfstate.d/*/terraform.tfstate"
+)
+
+// terraform init records the selected provider versions in the dependency
+// lock file of the root module
+const lockFileName = ".terraform.lock.hcl"
+
 // Use when parsing any TF file to prevent concurrent map read and write errors
 var parseMutex = sync.Mutex{}
 
@@ -43,20 +69,35 @@ func tfConfigList(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 	// are not supported in this context since the output value for the column
 	// will never match the requested value.
 	quals := d.EqualsQuals
+
+	// The workspaces requested through qualifier, either as a single value or
+	// as an in list
+	workspaces := getEqualsQualStringValues(d, "workspace")
+
 	if quals["path"] != nil {
 
 		path := d.EqualsQualString("path")
 
 		// check if state file is provide in the qual
 		if strings.HasSuffix(path, ".tfstate") {
-			d.StreamListItem(ctx, filePath{Path: path, IsTFStateFilePath: true})
+			workspace := getStateFileWorkspace(path)
+			if quals["workspace"] == nil || workspaces[workspace] {
+				d.StreamListItem(ctx, filePath{Path: path, IsTFStateFilePath: true, Workspace: workspace})
+			}
 			return nil, nil
 		}
 
-		d.StreamListItem(ctx, filePath{Path: path})
+		// Only state files belong to a workspace
+		if quals["workspace"] == nil {
+			d.StreamListItem(ctx, filePath{Path: path})
+		}
 		return nil, nil
 	}
 
+	// If the workspace was requested through qualifier then only state files
+	// are listed
+	workspaceQual := quals["workspace"] != nil
+
 	// #2 - paths in config
 
 	// Fail if no paths are specified
@@ -96,7 +137,7 @@ func tfConfigList(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 	for _, i := range matches {
 
 		// Ignore directories
-		if filehelpers.DirectoryExists(i) {
+		if filehelpers.DirectoryExists(i) || workspaceQual {
 			continue
 		}
 		d.StreamListItem(ctx, filePath{Path: i})
@@ -125,7 +166,7 @@ func tfConfigList(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 	for _, i := range matchedPlanFilePaths {
 
 		// Ignore directories
-		if filehelpers.DirectoryExists(i) {
+		if filehelpers.DirectoryExists(i) || workspaceQual {
 			continue
 		}
 		d.StreamListItem(ctx, filePath{
@@ -153,21 +194,173 @@ func tfConfigList(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateDat
 		matchedStateFilePaths = append(matchedStateFilePaths, files...)
 	}
 
-	// Sanitize the matches to ignore the directories
+	// Discover the states of the other workspaces of the local backend, next to
+	// the matched states of default workspaces
+	matchedStateFilePaths = append(matchedStateFilePaths, getLocalWorkspaceStateFilePaths(matchedStateFilePaths)...)
+
+	// Sanitize the matches to ignore the directories and duplicates
+	streamedStateFilePaths := map[string]bool{}
 	for _, i := range matchedStateFilePaths {
 
 		// Ignore directories
-		if filehelpers.DirectoryExists(i) {
+		if filehelpers.DirectoryExists(i) || streamedStateFilePaths[i] {
+			continue
+		}
+		streamedStateFilePaths[i] = true
+
+		workspace := getStateFileWorkspace(i)
+		if workspaceQual && !workspaces[workspace] {
 			continue
 		}
 		d.StreamListItem(ctx, filePath{
 			Path:              i,
 			IsTFStateFilePath: true,
+			Workspace:         workspace,
 		})
 	}
 	return nil, nil
 }
 
+// getEqualsQualStringValues returns the values requested for a column with an
+// equal qualifier in the where clause, including each value of an in list
+func getEqualsQualStringValues(d *plugin.QueryData, column string) map[string]bool {
+	values := map[string]bool{}
+	keyColumnQuals, ok := d.Quals[column]
+	if !ok {
+		return values
+	}
+	for _, qual := range keyColumnQuals.Quals {
+		if qual.Operator != quals.QualOperatorEqual {
+			continue
+		}
+		if listValue := qual.Value.GetListValue(); listValue != nil {
+			for _, value := range listValue.Values {
+				values[value.GetStringValue()] = true
+			}
+		} else {
+			values[qual.Value.GetStringValue()] = true
+		}
+	}
+	return values
+}
+
+// getLocalWorkspaceStateFilePaths returns the state files of the other
+// workspaces of the local backend, for the given state files which are the
+// state of the default workspace of a local backend. Other state files are not
+// expanded, so that only the root modules matched by the configured paths are
+// scanned.
+func getLocalWorkspaceStateFilePaths(paths []string) []string {
+	var stateFilePaths []string
+	dirs := map[string]bool{}
+	for _, path := range paths {
+		if getStateFileWorkspace(path) != defaultWorkspaceName {
+			continue
+		}
+		dir := filepath.Dir(path)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+
+		matches, err := filepath.Glob(filepath.Join(dir, localWorkspaceStateGlob))
+		if err != nil {
+			continue
+		}
+		stateFilePaths = append(stateFilePaths, matches...)
+	}
+	return stateFilePaths
+}
+
+// getStateFileWorkspace returns the workspace of a state file of the local
+// backend, or an empty string if the file does not follow its layout. A
+// terraform.tfstate file is only the state of the default workspace if its
+// directory looks like the root module of a local backend, i.e., it has been
+// initialized or has other workspaces, rather than e.g. a copy of a state
+// pulled from a remote backend.
+func getStateFileWorkspace(path string) string {
+	if filepath.Base(path) != localStateFileName {
+		return ""
+	}
+	dir := filepath.Dir(path)
+	if filepath.Base(filepath.Dir(dir)) == localWorkspacesDirName {
+		return filepath.Base(dir)
+	}
+	if filehelpers.DirectoryExists(filepath.Join(dir, localDataDirName)) || filehelpers.DirectoryExists(filepath.Join(dir, localWorkspacesDirName)) {
+		return defaultWorkspaceName
+	}
+	return ""
+}
+
+// getStateFileRootDir returns the directory of the root module of a state
+// file. The states of the workspaces of the local backend are stored in a
+// sub-directory of the root module.
+func getStateFileRootDir(path string) string {
+	dir := filepath.Dir(path)
+	if filepath.Base(path) == localStateFileName && filepath.Base(filepath.Dir(dir)) == localWorkspacesDirName {
+		return filepath.Dir(filepath.Dir(dir))
+	}
+	return dir
+}
+
+// tfLockFileList lists the dependency lock files in the directories of the
+// matched configuration files
+func tfLockFileList(ctx context.Context, d *plugin.QueryData, _ *plugin.HydrateData) (interface{}, error) {
+
+	// If the path was requested through qualifier then match it exactly
+	if d.EqualsQuals["path"] != nil {
+		path := d.EqualsQualString("path")
+		if filepath.Base(path) == lockFileName && filehelpers.FileExists(path) {
+			d.StreamListItem(ctx, filePath{Path: path})
+		}
+		return nil, nil
+	}
+
+	// TODO:: Remove backward compatibility for the argument 'Paths'
+	terraformConfig := GetConfig(d.Connection)
+	configurationFilePaths := terraformConfig.ConfigurationFilePaths
+	if terraformConfig.Paths != nil {
+		configurationFilePaths = terraformConfig.Paths
+	}
+
+	var matches []string
+	for _, i := range configurationFilePaths {
+
+		// List the files in the given source directory
+		files, err := d.GetSourceFiles(i)
+		if err != nil {
+			plugin.Logger(ctx).Error("tfLockFileList.configurationFilePaths", "get_source_files_error", err)
+
+			// If the specified path is unavailable, then an empty row should populate
+			if strings.Contains(err.Error(), "failed to get directory specified by the source") {
+				continue
+			}
+			return nil, err
+		}
+		matches = append(matches, files...)
+	}
+
+	// Stream the lock file of each directory once
+	dirs := map[string]bool{}
+	for _, i := range matches {
+
+		// Ignore directories
+		if filehelpers.DirectoryExists(i) {
+			continue
+		}
+		dir := filepath.Dir(i)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+
+		path := filepath.Join(dir, lockFileName)
+		if filehelpers.FileExists(path) {
+			d.StreamListItem(ctx, filePath{Path: path})
+		}
+	}
+	return nil, nil
+}
+
 func Parser() ([]*parser.Parser, error) {
 
 	combinedParser, err := parser.NewBuilder().
@@ -354,10 +547,17 @@ var terraformSchema = &hcl.BodySchema{
 		{
 			Type: "moved",
 		},
+		{
+			Type: "import",
+		},
 	},
 }
 
 func isTerraformPlan(content []byte) bool {
+	if isTerraformBinaryPlan(content) {
+		return true
+	}
+
 	var data map[string]interface{}
 	err := json.Unmarshal(content, &data)
 	if err != nil {
@@ -422,7 +622,9 @@ func findBlockLinesFromJSON(ctx context.Context, path string, blockName string,
 			// For terraform plan we need a special handling since
 			// if we use the count or for_each, in that case the resource configurations in the terraform plan can have more than 1 resource object with same name and type.
 			// So, to avoid the conflict use address and type instead which is unique and only applicable for terraform plan file.
-			if inBlock && strings.Contains(trimmedLine, fmt.Sprintf(`"address": "%s"`, pathName[0])) {
+			// The address is matched in its JSON encoded form, since addresses of resources created using for_each contain quoted keys.
+			quotedAddress, _ := json.Marshal(pathName[0])
+			if inBlock && !inTargetBlock && strings.Contains(trimmedLine, fmt.Sprintf(`"address": %s`, quotedAddress)) {
 				peekCounter := 1
 				nameFound := false
 
@@ -441,28 +643,29 @@ func findBlockLinesFromJSON(ctx context.Context, path string, blockName string,
 				if nameFound {
 					inTargetBlock = true
 					startLine = startCounter // Assume the opening brace is at the start of this resource
+
+					// The resource may be nested in a child module, so only count the brackets of the resource itself
+					bracketCounter = 1
 				}
 			}
 
 			// Get the start line info from terraform state file.
-			// Match the type and name of the resource to get the start position
-			if inBlock && strings.Contains(trimmedLine, fmt.Sprintf(`"type": "%s"`, pathName[0])) {
-				peekCounter := 1
-				nameFound := false
-
-				for {
-					peekLine, _ := readLineN(file, currentLine+peekCounter)
-					if strings.Contains(peekLine, fmt.Sprintf(`"name": "%s"`, pathName[1])) {
-						nameFound = true
-						break
-					}
-					if strings.Contains(peekLine, "}") {
-						break
+			// Match the module, mode, type and name of the resource to get the start position, since
+			// resources in different modules, or managed and data resources, may share the same type and name.
+			if inBlock && !inTargetBlock && strings.Contains(trimmedLine, fmt.Sprintf(`"type": "%s"`, pathName[0])) {
+				properties := readJSONObjectProperties(file, startCounter)
+				moduleAddress, mode := "", "managed"
+				if len(pathName) > 3 {
+					moduleAddress = pathName[2]
+					if pathName[3] != "" {
+						mode = pathName[3]
 					}
-					peekCounter++
+				}
+				if properties["mode"] == "" {
+					properties["mode"] = "managed"
 				}
 
-				if nameFound {
+				if properties["type"] == pathName[0] && properties["name"] == pathName[1] && properties["module"] == moduleAddress && properties["mode"] == mode {
 					inTargetBlock = true
 					startLine = startCounter // Assume the opening brace is at the start of this resource
 				}
@@ -504,44 +707,24 @@ func findBlockLinesFromJSON(ctx context.Context, path string, blockName string,
 			plugin.Logger(ctx).Error("findBlockLinesFromJSON", "read_file_error", err)
 			return startLine, endLine, source, err
 		}
-		contentStr := string(content)
-
-		// Regex pattern to extract the resources list from the file
-		pattern := `"planned_values":{.*"root_module":{"resources":(.*)}},"resource_changes"`
 
-		// Compile the regular expression
-		re := regexp.MustCompile(pattern)
-
-		// Find the match in the JSON string
-		matches := re.FindStringSubmatch(contentStr)
-
-		// Check if the resources block is present in the plan file content store the resources list
-		var resources []interface{}
-		if len(matches) >= 2 {
-			plannedValues := matches[1]
-			err := json.Unmarshal([]byte(plannedValues), &resources)
-			if err != nil {
-				plugin.Logger(ctx).Error("findBlockLinesFromJSON", "unmarshal_error", err)
-				return startLine, endLine, source, err
-			}
+		// Extract the resources list from the plan file content, including the
+		// resources declared in any child modules
+		var planContent struct {
+			PlannedValues TerraformPlanPlannedValues `json:"planned_values"`
+		}
+		err = json.Unmarshal(content, &planContent)
+		if err != nil {
+			plugin.Logger(ctx).Error("findBlockLinesFromJSON", "unmarshal_error", err)
+			return startLine, endLine, source, err
 		}
+		resources := getTerraformPlanModuleResources(planContent.PlannedValues.RootModule)
 
-		// Go through the resources and check for the desired one
+		// Go through the resources and check for the desired one, and set its
+		// JSON encoding as source
 		for _, r := range resources {
-			if strings.Contains(fmt.Sprint(r), pathName[0]) && strings.Contains(fmt.Sprint(r), pathName[1]) {
-				if data, ok := r.(map[string]interface{}); ok {
-					// Marshal the map to JSON
-					jsonBytes, err := json.Marshal(data)
-					if err != nil {
-						plugin.Logger(ctx).Error("findBlockLinesFromJSON", "unmarshal_error", err)
-						return startLine, endLine, source, err
-					}
-
-					// Convert the JSON bytes to a string
-					jsonString := string(jsonBytes)
-					// And, set the value as source
-					source = jsonString
-				}
+			if len(pathName) > 1 && r.Address == pathName[0] && r.Type == pathName[1] {
+				source = string(r.Raw)
 			}
 		}
 	}
@@ -566,6 +749,36 @@ func getSourceFromFile(file *os.File, startLine int, endLine int) string {
 	return source
 }
 
+// readJSONObjectProperties returns the string properties of the JSON object
+// opened at the given line, up to its first nested object or array. Each
+// property is expected on its own line, as in the state files written by
+// Terraform.
+func readJSONObjectProperties(file *os.File, startLine int) map[string]string {
+	properties := map[string]string{}
+	_, _ = file.Seek(0, 0) // Go to the start
+	scanner := bufio.NewScanner(file)
+	currentLine := 0
+	for scanner.Scan() {
+		currentLine++
+		if currentLine <= startLine {
+			continue
+		}
+		// The first nested object or array, or the end of the object, is not a
+		// complete property on its own
+		line := strings.TrimSuffix(strings.TrimSpace(scanner.Text()), ",")
+		var property map[string]interface{}
+		if err := json.Unmarshal([]byte("{"+line+"}"), &property); err != nil {
+			break
+		}
+		for k, v := range property {
+			if value, ok := v.(string); ok {
+				properties[k] = value
+			}
+		}
+	}
+	return properties
+}
+
 func readLineN(file *os.File, lineNum int) (string, error) {
 	_, _ = file.Seek(0, 0) // Go to the start
 	scanner := bufio.NewScanner(file)
@@ -588,3 +801,264 @@ func NullIfEmptyMap(_ context.Context, d *transform.TransformData) (interface{},
 	}
 	return d.Value, nil
 }
+
+// buildResourceInstanceAddress forms the absolute address of a resource
+// instance, in the same format as the addresses printed by Terraform, e.g.
+// module.vpc.data.aws_subnet.this["a"]
+func buildResourceInstanceAddress(moduleAddress string, mode string, resourceType string, name string, indexKey interface{}) string {
+	address := fmt.Sprintf("%s.%s", resourceType, name)
+	if mode == "data" {
+		address = "data." + address
+	}
+	if moduleAddress != "" {
+		address = moduleAddress + "." + address
+	}
+	return address + formatInstanceKey(indexKey)
+}
+
+// formatInstanceKey returns the instance key suffix of an address, for the
+// instances created using count (numbers) or for_each (strings)
+func formatInstanceKey(indexKey interface{}) string {
+	switch key := indexKey.(type) {
+	case float64:
+		return fmt.Sprintf("[%s]", strconv.FormatFloat(key, 'f', -1, 64))
+	case int:
+		return fmt.Sprintf("[%d]", key)
+	case string:
+		return "[" + quoteHCLString(key) + "]"
+	}
+	return ""
+}
+
+// quoteHCLString quotes a string the same way Terraform quotes the string
+// instance keys of addresses, using the escape sequences of HCL. Unlike Go
+// quoting, printable non-ASCII characters are kept as is, and template
+// sequences are escaped.
+func quoteHCLString(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '$', '%':
+			// Double the template introducers to escape them
+			builder.WriteRune(r)
+			if strings.HasPrefix(s[i+1:], "{") {
+				builder.WriteRune(r)
+			}
+		default:
+			switch {
+			case unicode.IsPrint(r):
+				builder.WriteRune(r)
+			case r < 0x10000:
+				builder.WriteString(fmt.Sprintf("\\u%04x", r))
+			default:
+				builder.WriteString(fmt.Sprintf("\\U%08x", r))
+			}
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// parseResourceInstanceAddress splits the absolute address of a resource
+// instance into the address of its module, its mode, type, name and instance
+// key. Instance keys are returned as float64 or string values, as they would
+// be decoded from JSON.
+func parseResourceInstanceAddress(address string) (moduleAddress string, mode string, resourceType string, name string, indexKey interface{}, err error) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", "", "", "", nil, fmt.Errorf("invalid resource address %s: %s", address, diags.Error())
+	}
+
+	// Collect the traversal steps as names, each optionally followed by an instance key
+	var names []string
+	var keys []interface{}
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, step.Name)
+			keys = append(keys, nil)
+		case hcl.TraverseAttr:
+			names = append(names, step.Name)
+			keys = append(keys, nil)
+		case hcl.TraverseIndex:
+			if len(keys) == 0 || keys[len(keys)-1] != nil {
+				return "", "", "", "", nil, fmt.Errorf("invalid resource address %s", address)
+			}
+			if step.Key.Type() == cty.Number {
+				f, _ := step.Key.AsBigFloat().Float64()
+				keys[len(keys)-1] = f
+			} else if step.Key.Type() == cty.String {
+				keys[len(keys)-1] = step.Key.AsString()
+			}
+		}
+	}
+
+	// Module path
+	var modulePath []string
+	i := 0
+	for i+1 < len(names) && names[i] == "module" {
+		modulePath = append(modulePath, fmt.Sprintf("module.%s%s", names[i+1], formatInstanceKey(keys[i+1])))
+		i += 2
+	}
+	moduleAddress = strings.Join(modulePath, ".")
+
+	// Resource
+	mode = "managed"
+	if i < len(names) && names[i] == "data" {
+		mode = "data"
+		i++
+	}
+	if len(names)-i != 2 {
+		return "", "", "", "", nil, fmt.Errorf("invalid resource address %s", address)
+	}
+
+	return moduleAddress, mode, names[i], names[i+1], keys[i+1], nil
+}
+
+// getModuleConfigAddress returns the address of a module without the instance
+// keys of the module calls using count or for_each, e.g. module.vpc.module.subnets
+// for module.vpc["a"].module.subnets[0]
+func getModuleConfigAddress(moduleAddress string) string {
+	if moduleAddress == "" {
+		return ""
+	}
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(moduleAddress), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return moduleAddress
+	}
+
+	var names []string
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			names = append(names, step.Name)
+		case hcl.TraverseAttr:
+			names = append(names, step.Name)
+		}
+	}
+	return strings.Join(names, ".")
+}
+
+// parseProviderConfigAddress splits the absolute address of a provider
+// configuration, as recorded in state files, into the address of its module,
+// the source address of the provider and its alias, e.g.
+// module.vpc.provider["registry.terraform.io/hashicorp/aws"].west. The legacy
+// format of Terraform 0.12, e.g. provider.aws.west, is supported as well.
+func parseProviderConfigAddress(address string) (moduleAddress string, source string, alias string, err error) {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(address), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", "", "", fmt.Errorf("invalid provider address %s: %s", address, diags.Error())
+	}
+
+	// Module path
+	var modulePath []string
+	i := 0
+	for i+1 < len(traversal) {
+		if name, ok := getTraverserName(traversal[i]); !ok || name != "module" {
+			break
+		}
+		name, ok := getTraverserName(traversal[i+1])
+		if !ok {
+			return "", "", "", fmt.Errorf("invalid provider address %s", address)
+		}
+		step := "module." + name
+		i += 2
+		if i < len(traversal) {
+			if index, ok := traversal[i].(hcl.TraverseIndex); ok {
+				if index.Key.Type() == cty.String {
+					step += "[" + quoteHCLString(index.Key.AsString()) + "]"
+				} else if index.Key.Type() == cty.Number {
+					step += "[" + index.Key.AsBigFloat().Text('f', -1) + "]"
+				}
+				i++
+			}
+		}
+		modulePath = append(modulePath, step)
+	}
+	moduleAddress = strings.Join(modulePath, ".")
+
+	// Provider
+	if i+1 >= len(traversal) {
+		return "", "", "", fmt.Errorf("invalid provider address %s", address)
+	}
+	if name, ok := getTraverserName(traversal[i]); !ok || name != "provider" {
+		return "", "", "", fmt.Errorf("invalid provider address %s", address)
+	}
+	switch step := traversal[i+1].(type) {
+	case hcl.TraverseIndex:
+		if step.Key.Type() != cty.String {
+			return "", "", "", fmt.Errorf("invalid provider address %s", address)
+		}
+		source = step.Key.AsString()
+	case hcl.TraverseAttr:
+		source = step.Name
+	}
+	i += 2
+
+	// Alias
+	if i < len(traversal) {
+		if name, ok := getTraverserName(traversal[i]); ok {
+			alias = name
+		}
+	}
+
+	return moduleAddress, source, alias, nil
+}
+
+// parseProviderSource splits the source address of a provider into its
+// hostname, namespace and type, e.g. registry.terraform.io, hashicorp and aws
+// for registry.terraform.io/hashicorp/aws. The hostname defaults to the public
+// registry. Legacy addresses of Terraform 0.12 only contain the type.
+func parseProviderSource(source string) (hostname string, namespace string, providerType string) {
+	parts := strings.Split(source, "/")
+	switch len(parts) {
+	case 3:
+		return parts[0], parts[1], parts[2]
+	case 2:
+		return "registry.terraform.io", parts[0], parts[1]
+	}
+	return "", "", source
+}
+
+// renderTraversal renders a traversal in HCL native syntax, e.g.
+// aws_instance.web["a"].id
+func renderTraversal(traversal hcl.Traversal) string {
+	var builder strings.Builder
+	for _, step := range traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			builder.WriteString(step.Name)
+		case hcl.TraverseAttr:
+			builder.WriteString("." + step.Name)
+		case hcl.TraverseIndex:
+			switch {
+			case step.Key.Type() == cty.String && step.Key.IsKnown():
+				builder.WriteString("[" + quoteHCLString(step.Key.AsString()) + "]")
+			case step.Key.Type() == cty.Number && step.Key.IsKnown():
+				builder.WriteString("[" + step.Key.AsBigFloat().Text('f', -1) + "]")
+			}
+		}
+	}
+	return builder.String()
+}
+
+func getTraverserName(traverser hcl.Traverser) (string, bool) {
+	switch step := traverser.(type) {
+	case hcl.TraverseRoot:
+		return step.Name, true
+	case hcl.TraverseAttr:
+		return step.Name, true
+	}
+	return "", false
+}
@@ -0,0 +1,290 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseResourceInstanceAddress(t *testing.T) {
+	tests := []struct {
+		address       string
+		moduleAddress string
+		mode          string
+		resourceType  string
+		name          string
+		indexKey      interface{}
+		wantErr       bool
+	}{
+		{address: "aws_instance.web", mode: "managed", resourceType: "aws_instance", name: "web"},
+		{address: "data.aws_ami.ubuntu", mode: "data", resourceType: "aws_ami", name: "ubuntu"},
+		{address: "aws_instance.web[0]", mode: "managed", resourceType: "aws_instance", name: "web", indexKey: float64(0)},
+		{address: `aws_instance.web["a"]`, mode: "managed", resourceType: "aws_instance", name: "web", indexKey: "a"},
+		{address: `aws_instance.web["café"]`, mode: "managed", resourceType: "aws_instance", name: "web", indexKey: "café"},
+		{address: "module.vpc.aws_subnet.this[2]", moduleAddress: "module.vpc", mode: "managed", resourceType: "aws_subnet", name: "this", indexKey: float64(2)},
+		{
+			address:       `module.vpc["a"].module.subnets[0].data.aws_subnet.this["x"]`,
+			moduleAddress: `module.vpc["a"].module.subnets[0]`,
+			mode:          "data",
+			resourceType:  "aws_subnet",
+			name:          "this",
+			indexKey:      "x",
+		},
+		{address: "module.vpc", wantErr: true},
+		{address: "data.aws_ami", wantErr: true},
+		{address: "aws_instance.web[0][1]", wantErr: true},
+		{address: "aws_instance.", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.address, func(t *testing.T) {
+			moduleAddress, mode, resourceType, name, indexKey, err := parseResourceInstanceAddress(test.address)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if moduleAddress != test.moduleAddress || mode != test.mode || resourceType != test.resourceType || name != test.name || !reflect.DeepEqual(indexKey, test.indexKey) {
+				t.Errorf("got %q, %q, %q, %q, %#v, want %q, %q, %q, %q, %#v", moduleAddress, mode, resourceType, name, indexKey,
+					test.moduleAddress, test.mode, test.resourceType, test.name, test.indexKey)
+			}
+
+			// The parsed address builds back to the same address
+			if address := buildResourceInstanceAddress(moduleAddress, mode, resourceType, name, indexKey); address != test.address {
+				t.Errorf("got built address %s, want %s", address, test.address)
+			}
+		})
+	}
+}
+
+func TestBuildResourceInstanceAddress(t *testing.T) {
+	tests := []struct {
+		moduleAddress string
+		mode          string
+		resourceType  string
+		name          string
+		indexKey      interface{}
+		want          string
+	}{
+		{mode: "managed", resourceType: "aws_instance", name: "web", want: "aws_instance.web"},
+		{mode: "data", resourceType: "aws_ami", name: "ubuntu", want: "data.aws_ami.ubuntu"},
+		{mode: "managed", resourceType: "aws_instance", name: "web", indexKey: float64(1), want: "aws_instance.web[1]"},
+		{mode: "managed", resourceType: "aws_instance", name: "web", indexKey: 1, want: "aws_instance.web[1]"},
+		{mode: "managed", resourceType: "aws_instance", name: "web", indexKey: float64(10000000), want: "aws_instance.web[10000000]"},
+		{mode: "managed", resourceType: "aws_instance", name: "web", indexKey: "a", want: `aws_instance.web["a"]`},
+		{mode: "managed", resourceType: "aws_instance", name: "web", indexKey: "café", want: `aws_instance.web["café"]`},
+		{moduleAddress: `module.vpc["a"].module.subnets[0]`, mode: "data", resourceType: "aws_subnet", name: "this", indexKey: "x", want: `module.vpc["a"].module.subnets[0].data.aws_subnet.this["x"]`},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := buildResourceInstanceAddress(test.moduleAddress, test.mode, test.resourceType, test.name, test.indexKey); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestQuoteHCLString(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{s: "", want: `""`},
+		{s: "a", want: `"a"`},
+		{s: "café", want: `"café"`},
+		{s: "日本", want: `"日本"`},
+		{s: `a"b\c`, want: `"a\"b\\c"`},
+		{s: "a\nb\tc", want: `"a\nb\tc"`},
+		{s: "\x00\u200b", want: `"\u0000\u200b"`},
+		{s: "${a} %{b} $c", want: `"$${a} %%{b} $c"`},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := quoteHCLString(test.s); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetModuleConfigAddress(t *testing.T) {
+	tests := []struct {
+		moduleAddress string
+		want          string
+	}{
+		{moduleAddress: "", want: ""},
+		{moduleAddress: "module.vpc", want: "module.vpc"},
+		{moduleAddress: "module.vpc[0]", want: "module.vpc"},
+		{moduleAddress: `module.vpc["a"].module.subnets[0]`, want: "module.vpc.module.subnets"},
+	}
+	for _, test := range tests {
+		t.Run(test.moduleAddress, func(t *testing.T) {
+			if got := getModuleConfigAddress(test.moduleAddress); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseProviderConfigAddress(t *testing.T) {
+	tests := []struct {
+		address       string
+		moduleAddress string
+		source        string
+		alias         string
+		wantErr       bool
+	}{
+		{address: `provider["registry.terraform.io/hashicorp/aws"]`, source: "registry.terraform.io/hashicorp/aws"},
+		{address: `provider["registry.terraform.io/hashicorp/aws"].west`, source: "registry.terraform.io/hashicorp/aws", alias: "west"},
+		{address: `module.vpc.provider["registry.terraform.io/hashicorp/aws"]`, moduleAddress: "module.vpc", source: "registry.terraform.io/hashicorp/aws"},
+		{
+			address:       `module.vpc["a"].module.subnets[0].provider["registry.terraform.io/hashicorp/aws"].west`,
+			moduleAddress: `module.vpc["a"].module.subnets[0]`,
+			source:        "registry.terraform.io/hashicorp/aws",
+			alias:         "west",
+		},
+		{address: `module.vpc["é"].provider["registry.terraform.io/hashicorp/aws"]`, moduleAddress: `module.vpc["é"]`, source: "registry.terraform.io/hashicorp/aws"},
+		{address: "provider.aws", source: "aws"},
+		{address: "provider.aws.west", source: "aws", alias: "west"},
+		{address: "module.vpc.provider.aws.west", moduleAddress: "module.vpc", source: "aws", alias: "west"},
+		{address: "aws", wantErr: true},
+		{address: "module.vpc.aws", wantErr: true},
+		{address: "provider[0]", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.address, func(t *testing.T) {
+			moduleAddress, source, alias, err := parseProviderConfigAddress(test.address)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if moduleAddress != test.moduleAddress || source != test.source || alias != test.alias {
+				t.Errorf("got %q, %q, %q, want %q, %q, %q", moduleAddress, source, alias, test.moduleAddress, test.source, test.alias)
+			}
+		})
+	}
+}
+
+// createTestWorkspaceLayout creates the given files and directories, those
+// ending with a slash, under a temporary directory
+func createTestWorkspaceLayout(t *testing.T, paths ...string) string {
+	root := t.TempDir()
+	for _, path := range paths {
+		isDir := strings.HasSuffix(path, "/")
+		path = filepath.Join(root, filepath.FromSlash(path))
+		if isDir {
+			if err := os.MkdirAll(path, 0700); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestGetStateFileWorkspace(t *testing.T) {
+	root := createTestWorkspaceLayout(t,
+		"initialized/.terraform/",
+		"initialized/terraform.tfstate",
+		"workspaces/terraform.tfstate",
+		"workspaces/terraform.tfstate.d/prod/terraform.tfstate",
+		"copy/terraform.tfstate",
+		"copy/other.tfstate",
+	)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "initialized/terraform.tfstate", want: "default"},
+		{path: "workspaces/terraform.tfstate", want: "default"},
+		{path: "workspaces/terraform.tfstate.d/prod/terraform.tfstate", want: "prod"},
+		{path: "copy/terraform.tfstate", want: ""},
+		{path: "copy/other.tfstate", want: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if got := getStateFileWorkspace(filepath.Join(root, test.path)); got != test.want {
+				t.Errorf("got workspace %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetStateFileRootDir(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/infra/terraform.tfstate", want: "/infra"},
+		{path: "/infra/backup.tfstate", want: "/infra"},
+		{path: "/infra/terraform.tfstate.d/prod/terraform.tfstate", want: "/infra"},
+		{path: "/infra/terraform.tfstate.d/prod/other.tfstate", want: "/infra/terraform.tfstate.d/prod"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			if got := getStateFileRootDir(filepath.FromSlash(test.path)); got != filepath.FromSlash(test.want) {
+				t.Errorf("got root directory %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetLocalWorkspaceStateFilePaths(t *testing.T) {
+	root := createTestWorkspaceLayout(t,
+		"infra/terraform.tfstate",
+		"infra/other.tfstate",
+		"infra/terraform.tfstate.d/dev/terraform.tfstate",
+		"infra/terraform.tfstate.d/prod/terraform.tfstate",
+	)
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{
+			name:  "default workspace state",
+			paths: []string{"infra/terraform.tfstate"},
+			want:  []string{"infra/terraform.tfstate.d/dev/terraform.tfstate", "infra/terraform.tfstate.d/prod/terraform.tfstate"},
+		},
+		{
+			name:  "other state file",
+			paths: []string{"infra/other.tfstate"},
+		},
+		{
+			name:  "workspace state",
+			paths: []string{"infra/terraform.tfstate.d/dev/terraform.tfstate"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var paths, want []string
+			for _, path := range test.paths {
+				paths = append(paths, filepath.Join(root, filepath.FromSlash(path)))
+			}
+			for _, path := range test.want {
+				want = append(want, filepath.Join(root, filepath.FromSlash(path)))
+			}
+			if got := getLocalWorkspaceStateFilePaths(paths); !reflect.DeepEqual(got, want) {
+				t.Errorf("got state files %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/turbot/steampipe-plugin-sdk/v5/plugin/transform"
+)
+
+// Version constraints are evaluated with the same library as Terraform uses
+// for the required_version and module version arguments, e.g. ~> 4.0 allows
+// any version greater than or equal to 4.0.0 and lower than 5.0.0.
+
+// versionConstraintRegexp matches the operator and the version segments of a
+// single constraint, e.g. ~> and 4.0 for ~> 4.0
+var versionConstraintRegexp = regexp.MustCompile(`^\s*(=|!=|>=|<=|>|<|~>)?\s*v?([0-9]+(\.[0-9]+)*)`)
+
+// versionConstraintBounds is the range of versions allowed by a version
+// constraint. A nil version means the range is unbounded.
+type versionConstraintBounds struct {
+	Min          *version.Version
+	MinInclusive bool
+	Max          *version.Version
+	MaxInclusive bool
+}
+
+// getVersionConstraintBounds returns the range of versions allowed by a
+// version constraint, i.e., the intersection of the ranges of its comma
+// separated constraints. Exclusions, e.g. != 4.1.0, are not taken into account.
+func getVersionConstraintBounds(constraint string) (*versionConstraintBounds, error) {
+	if _, err := version.NewConstraint(constraint); err != nil {
+		return nil, err
+	}
+
+	bounds := &versionConstraintBounds{}
+	for _, single := range strings.Split(constraint, ",") {
+		// The constraint has been validated, so each part matches
+		matches := versionConstraintRegexp.FindStringSubmatch(single)
+		operator := matches[1]
+		v, err := version.NewVersion(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(single), "=!<>~")))
+		if err != nil {
+			return nil, err
+		}
+
+		switch operator {
+		case "", "=":
+			bounds.setMin(v, true)
+			bounds.setMax(v, true)
+		case ">=":
+			bounds.setMin(v, true)
+		case ">":
+			bounds.setMin(v, false)
+		case "<=":
+			bounds.setMax(v, true)
+		case "<":
+			bounds.setMax(v, false)
+		case "~>":
+			// Only the rightmost specified segment can increase, e.g. ~> 4.0.3
+			// allows 4.0.x and ~> 4.0 allows 4.x. A single segment, e.g. ~> 4,
+			// has no upper bound.
+			bounds.setMin(v, true)
+			segmentCount := len(strings.Split(matches[2], "."))
+			if segmentCount > 1 {
+				segments := v.Segments64()
+				max := make([]string, len(segments))
+				for i := range segments {
+					switch {
+					case i < segmentCount-2:
+						max[i] = fmt.Sprint(segments[i])
+					case i == segmentCount-2:
+						max[i] = fmt.Sprint(segments[i] + 1)
+					default:
+						max[i] = "0"
+					}
+				}
+				bounds.setMax(version.Must(version.NewVersion(strings.Join(max, "."))), false)
+			}
+		}
+	}
+	return bounds, nil
+}
+
+// setMin restricts the range to the versions greater than a lower bound
+func (b *versionConstraintBounds) setMin(v *version.Version, inclusive bool) {
+	if b.Min == nil || v.GreaterThan(b.Min) {
+		b.Min, b.MinInclusive = v, inclusive
+	} else if v.Equal(b.Min) {
+		b.MinInclusive = b.MinInclusive && inclusive
+	}
+}
+
+// setMax restricts the range to the versions lower than an upper bound
+func (b *versionConstraintBounds) setMax(v *version.Version, inclusive bool) {
+	if b.Max == nil || v.LessThan(b.Max) {
+		b.Max, b.MaxInclusive = v, inclusive
+	} else if v.Equal(b.Max) {
+		b.MaxInclusive = b.MaxInclusive && inclusive
+	}
+}
+
+// Transform function to return the lower bound of a version constraint, or
+// nil if the constraint is empty, invalid or has no lower bound
+func VersionConstraintMin(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	constraint, ok := d.Value.(string)
+	if !ok || constraint == "" {
+		return nil, nil
+	}
+	bounds, err := getVersionConstraintBounds(constraint)
+	if err != nil || bounds.Min == nil {
+		return nil, nil
+	}
+	return bounds.Min.String(), nil
+}
+
+// Transform function to return the upper bound of a version constraint, or
+// nil if the constraint is empty, invalid or has no upper bound
+func VersionConstraintMax(_ context.Context, d *transform.TransformData) (interface{}, error) {
+	constraint, ok := d.Value.(string)
+	if !ok || constraint == "" {
+		return nil, nil
+	}
+	bounds, err := getVersionConstraintBounds(constraint)
+	if err != nil || bounds.Max == nil {
+		return nil, nil
+	}
+	return bounds.Max.String(), nil
+}